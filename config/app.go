@@ -21,6 +21,15 @@ type Config struct {
 	// Database
 	DatabasePath string `yaml:"db_path"`
 
+	// Execution
+	MaxWorkers int `yaml:"max_workers"`
+
+	// Execution history retention. 0 disables each limit.
+	KeepExecutions      int  `yaml:"keep_executions"`        // newest executions kept per pipe
+	ExecutionMaxAgeDays int  `yaml:"execution_max_age_days"` // older executions are deleted
+	MaxLogPayload       int  `yaml:"max_log_payload"`        // bytes stored per log row
+	CompressLogs        bool `yaml:"compress_logs"`
+
 	// OAuth (Indiko)
 	IndikoURL          string `yaml:"indiko_url"`
 	IndikoClientID     string `yaml:"indiko_client_id"`
@@ -41,6 +50,8 @@ func Default() *Config {
 		Env:               "development",
 		LogLevel:          "info",
 		DatabasePath:      "pipes.db",
+		MaxWorkers:        8,
+		MaxLogPayload:     1 << 20,
 		IndikoURL:         "http://localhost:3000",
 		OAuthCallbackURL:  "http://localhost:3001/auth/callback",
 		SessionCookieName: "pipes_session",
@@ -49,6 +60,21 @@ func Default() *Config {
 
 // Load loads configuration from YAML file (if provided) and environment variables
 func Load(path string) (*Config, error) {
+	cfg, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Read loads configuration like Load, but without requiring the settings
+// only the server needs, for commands that just use the database
+func Read(path string) (*Config, error) {
 	cfg := Default()
 
 	// Load .env file if it exists (silently ignore if not found)
@@ -76,8 +102,8 @@ func Load(path string) (*Config, error) {
 	// Apply environment variable overrides
 	applyEnvOverrides(cfg)
 
-	if err := cfg.Validate(); err != nil {
-		return nil, err
+	if cfg.KeepExecutions < 0 || cfg.ExecutionMaxAgeDays < 0 || cfg.MaxLogPayload < 0 {
+		return nil, fmt.Errorf("keep_executions, execution_max_age_days and max_log_payload can't be negative")
 	}
 
 	return cfg, nil
@@ -140,6 +166,31 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("DATABASE_PATH"); v != "" {
 		cfg.DatabasePath = v
 	}
+	if v := os.Getenv("MAX_WORKERS"); v != "" {
+		if workers, err := strconv.Atoi(v); err == nil {
+			cfg.MaxWorkers = workers
+		}
+	}
+	if v := os.Getenv("KEEP_EXECUTIONS"); v != "" {
+		if keep, err := strconv.Atoi(v); err == nil {
+			cfg.KeepExecutions = keep
+		}
+	}
+	if v := os.Getenv("EXECUTION_MAX_AGE_DAYS"); v != "" {
+		if days, err := strconv.Atoi(v); err == nil {
+			cfg.ExecutionMaxAgeDays = days
+		}
+	}
+	if v := os.Getenv("MAX_LOG_PAYLOAD"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil {
+			cfg.MaxLogPayload = size
+		}
+	}
+	if v := os.Getenv("COMPRESS_LOGS"); v != "" {
+		if compress, err := strconv.ParseBool(v); err == nil {
+			cfg.CompressLogs = compress
+		}
+	}
 	if v := os.Getenv("INDIKO_URL"); v != "" {
 		cfg.IndikoURL = v
 	}
@@ -0,0 +1,274 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed 5-field cron expression (minute hour day-of-month
+// month day-of-week) evaluated in a specific time zone.
+type CronSchedule struct {
+	Expression string
+	Location   *time.Location
+
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// Standard cron semantics: when both day fields are restricted, a day
+	// matches if either of them matches.
+	domRestricted bool
+	dowRestricted bool
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a cron expression. An empty timezone means UTC.
+func ParseCron(expression, timezone string) (*CronSchedule, error) {
+	loc := time.UTC
+	if timezone != "" {
+		l, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+		loc = l
+	}
+
+	expr := strings.TrimSpace(expression)
+	if expr == "" {
+		return nil, fmt.Errorf("cron expression is empty")
+	}
+
+	if strings.HasPrefix(expr, "@") {
+		expanded, ok := cronMacros[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron macro: %s", expr)
+		}
+		expr = expanded
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	sched := &CronSchedule{
+		Expression: strings.TrimSpace(expression),
+		Location:   loc,
+	}
+
+	var err error
+	if sched.minute, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if sched.hour, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if sched.dom, err = parseCronField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if sched.month, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if sched.dow, err = parseCronField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+
+	// Sunday may be written as 0 or 7
+	if sched.dow&(1<<7) != 0 {
+		sched.dow = (sched.dow | 1) &^ (1 << 7)
+	}
+
+	sched.domRestricted = fields[2] != "*" && fields[2] != "?"
+	sched.dowRestricted = fields[4] != "*" && fields[4] != "?"
+
+	return sched, nil
+}
+
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(s, ",") {
+		if part == "" {
+			return 0, fmt.Errorf("invalid %s field: %q", f.name, s)
+		}
+
+		rangePart, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			rangePart = part[:idx]
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			lo, hi = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(bounds[1], f); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s field: %q", f.name, part)
+			}
+		default:
+			v, err := parseCronValue(rangePart, f)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			// "5/15" means every 15 starting at 5
+			if step > 1 {
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseCronValue(s string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value in %s field: %q", f.name, s)
+	}
+
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s value %d out of range (%d-%d)", f.name, v, f.min, f.max)
+	}
+
+	return v, nil
+}
+
+// Next returns the first fire time strictly after t, or the zero time if the
+// expression never fires (e.g. "0 0 30 2 *").
+//
+// Hours and minutes are stepped in absolute time, so a daylight saving
+// change can't stall the search. Times skipped when clocks go forward don't
+// fire; times repeated when they go back fire once, unless every hour
+// matches (e.g. @hourly), in which case both fire.
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	from := t
+	t = t.In(c.Location).Truncate(time.Minute).Add(time.Minute)
+
+	yearLimit := t.Year() + 5
+
+	for t.Year() <= yearLimit {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.Location)
+			continue
+		}
+
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.Location)
+			continue
+		}
+
+		if c.hour&(1<<uint(t.Hour())) == 0 || c.repeatedHour(t) {
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		if !t.After(from) {
+			return time.Time{}
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// allHours has a bit set for every hour of the day.
+const allHours = 1<<24 - 1
+
+// repeatedHour reports whether t falls in the second pass through an hour
+// that clocks went back over, which only fires for schedules that run
+// every hour.
+func (c *CronSchedule) repeatedHour(t time.Time) bool {
+	if c.hour == allHours {
+		return false
+	}
+	return t.Add(-time.Hour).Hour() == t.Hour()
+}
+
+// NextN returns up to n consecutive fire times after t.
+func (c *CronSchedule) NextN(t time.Time, n int) []time.Time {
+	var times []time.Time
+	for i := 0; i < n; i++ {
+		t = c.Next(t)
+		if t.IsZero() {
+			break
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case c.domRestricted && c.dowRestricted:
+		return domMatch || dowMatch
+	case c.domRestricted:
+		return domMatch
+	case c.dowRestricted:
+		return dowMatch
+	default:
+		return true
+	}
+}
@@ -0,0 +1,206 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestCronNext(t *testing.T) {
+	ny := mustLocation(t, "America/New_York")
+
+	tests := []struct {
+		name string
+		expr string
+		tz   string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "hourly macro",
+			expr: "@hourly",
+			from: time.Date(2026, 1, 10, 10, 15, 30, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 1, 10, 11, 0, 0, 0, time.UTC),
+				time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "daily macro",
+			expr: "@daily",
+			from: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "strictly after an exact match",
+			expr: "*/15 * * * *",
+			from: time.Date(2026, 1, 10, 10, 15, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 1, 10, 10, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			// The 15th (a Thursday) or any Monday
+			name: "day of month or day of week",
+			expr: "0 9 15 * mon",
+			from: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 1, 12, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 1, 19, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "day of week only",
+			expr: "0 9 * * 7",
+			from: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 1, 11, 9, 0, 0, 0, time.UTC),
+				time.Date(2026, 1, 18, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "spring forward skips the missing time",
+			expr: "30 2 * * *",
+			tz:   "America/New_York",
+			from: time.Date(2026, 3, 7, 12, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 9, 6, 30, 0, 0, time.UTC),
+				time.Date(2026, 3, 10, 6, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "spring forward",
+			expr: "0 3 * * *",
+			tz:   "America/New_York",
+			from: time.Date(2026, 3, 8, 0, 30, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 7, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 9, 7, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "fall back",
+			expr: "0 3 * * *",
+			tz:   "America/New_York",
+			from: time.Date(2026, 11, 1, 0, 30, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 2, 8, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "fall back after the repeated hour",
+			expr: "30 2 * * *",
+			tz:   "America/New_York",
+			from: time.Date(2026, 11, 1, 0, 30, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 7, 30, 0, 0, time.UTC),
+				time.Date(2026, 11, 2, 7, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "fall back runs a repeated time once",
+			expr: "30 1 * * *",
+			tz:   "America/New_York",
+			from: time.Date(2026, 11, 1, 0, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC), // 1:30 EDT
+				time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "fall back runs every hour for hourly schedules",
+			expr: "@hourly",
+			tz:   "America/New_York",
+			from: time.Date(2026, 11, 1, 0, 30, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 0, 0, 0, time.UTC), // 1:00 EDT
+				time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC), // 1:00 EST
+				time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "never fires",
+			expr: "0 0 30 2 *",
+			from: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := ParseCron(tt.expr, tt.tz)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+
+			got := sched.NextN(tt.from, len(tt.want)+1)
+			if tt.want == nil {
+				if len(got) != 0 {
+					t.Fatalf("got %v, want no fire times", got)
+				}
+				return
+			}
+			got = got[:min(len(got), len(tt.want))]
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d fire times %v, want %v", len(got), got, tt.want)
+			}
+			prev := tt.from
+			for i := range tt.want {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("fire time %d = %v, want %v", i, got[i].UTC(), tt.want[i])
+				}
+				if !got[i].After(prev) {
+					t.Errorf("fire time %d = %v is not after %v", i, got[i], prev)
+				}
+				prev = got[i]
+			}
+		})
+	}
+}
+
+// Every minute across both of a year's clock changes must advance.
+func TestCronNextAdvancesAcrossDST(t *testing.T) {
+	mustLocation(t, "America/New_York")
+
+	for _, expr := range []string{"0 3 * * *", "30 2 * * *", "30 1 * * *", "0 * * * *", "* * * * *", "15 0-5 * * *"} {
+		sched, err := ParseCron(expr, "America/New_York")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, start := range []time.Time{
+			time.Date(2026, 3, 8, 5, 0, 0, 0, time.UTC),
+			time.Date(2026, 11, 1, 4, 0, 0, 0, time.UTC),
+		} {
+			for from := start; from.Before(start.Add(4 * time.Hour)); from = from.Add(time.Minute) {
+				next := sched.Next(from)
+				if !next.After(from) {
+					t.Fatalf("%q: Next(%v) = %v", expr, from, next)
+				}
+			}
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "@often", "a * * * *"} {
+		if _, err := ParseCron(expr, ""); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+	if _, err := ParseCron("* * * * *", "Mars/Olympus"); err == nil {
+		t.Error("ParseCron with an unknown time zone succeeded, want error")
+	}
+}
@@ -0,0 +1,145 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// ConfigDiff is what changed from one pipe config to another. Nodes are
+// matched by ID and connections by their endpoints.
+type ConfigDiff struct {
+	AddedNodes         []Node        `json:"added_nodes"`
+	RemovedNodes       []Node        `json:"removed_nodes"`
+	ChangedNodes       []NodeChange  `json:"changed_nodes"`
+	AddedConnections   []Connection  `json:"added_connections"`
+	RemovedConnections []Connection  `json:"removed_connections"`
+	Settings           []FieldChange `json:"settings"`
+}
+
+// NodeChange lists the changed fields of a node in both configs. Config
+// fields are named config.<key>.
+type NodeChange struct {
+	NodeID  string        `json:"node_id"`
+	Type    string        `json:"type"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// FieldChange is a field's value before and after; From or To is nil if
+// the field was added or removed.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// DiffConfigs compares two pipe configs, which should both be at the
+// current version.
+func DiffConfigs(from, to *PipeConfig) *ConfigDiff {
+	diff := &ConfigDiff{
+		AddedNodes:         []Node{},
+		RemovedNodes:       []Node{},
+		ChangedNodes:       []NodeChange{},
+		AddedConnections:   []Connection{},
+		RemovedConnections: []Connection{},
+	}
+
+	fromNodes := make(map[string]Node, len(from.Nodes))
+	for _, n := range from.Nodes {
+		fromNodes[n.ID] = n
+	}
+	toNodes := make(map[string]bool, len(to.Nodes))
+
+	for _, n := range to.Nodes {
+		toNodes[n.ID] = true
+		old, ok := fromNodes[n.ID]
+		if !ok {
+			diff.AddedNodes = append(diff.AddedNodes, n)
+			continue
+		}
+		if changes := diffNode(old, n); len(changes) > 0 {
+			diff.ChangedNodes = append(diff.ChangedNodes, NodeChange{NodeID: n.ID, Type: n.Type, Changes: changes})
+		}
+	}
+	for _, n := range from.Nodes {
+		if !toNodes[n.ID] {
+			diff.RemovedNodes = append(diff.RemovedNodes, n)
+		}
+	}
+
+	fromConns := make(map[Connection]bool, len(from.Connections))
+	for _, c := range from.Connections {
+		fromConns[connectionEnds(c)] = true
+	}
+	toConns := make(map[Connection]bool, len(to.Connections))
+	for _, c := range to.Connections {
+		toConns[connectionEnds(c)] = true
+		if !fromConns[connectionEnds(c)] {
+			diff.AddedConnections = append(diff.AddedConnections, c)
+		}
+	}
+	for _, c := range from.Connections {
+		if !toConns[connectionEnds(c)] {
+			diff.RemovedConnections = append(diff.RemovedConnections, c)
+		}
+	}
+
+	diff.Settings = diffFields("", toMap(from.Settings), toMap(to.Settings))
+
+	return diff
+}
+
+func diffNode(from, to Node) []FieldChange {
+	var changes []FieldChange
+	if from.Type != to.Type {
+		changes = append(changes, FieldChange{Field: "type", From: from.Type, To: to.Type})
+	}
+	if from.Label != to.Label {
+		changes = append(changes, FieldChange{Field: "label", From: from.Label, To: to.Label})
+	}
+	if from.Position != to.Position {
+		changes = append(changes, FieldChange{Field: "position", From: from.Position, To: to.Position})
+	}
+	return append(changes, diffFields("config.", from.Config, to.Config)...)
+}
+
+// diffFields compares two maps key by key, in key order.
+func diffFields(prefix string, from, to map[string]interface{}) []FieldChange {
+	keys := make(map[string]bool, len(from)+len(to))
+	for k := range from {
+		keys[k] = true
+	}
+	for k := range to {
+		keys[k] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	changes := []FieldChange{}
+	for _, k := range sorted {
+		if !reflect.DeepEqual(from[k], to[k]) {
+			changes = append(changes, FieldChange{Field: prefix + k, From: from[k], To: to[k]})
+		}
+	}
+	return changes
+}
+
+// connectionEnds identifies a connection by what it connects rather than
+// its ID, so deleting and redrawing the same connection isn't a change.
+func connectionEnds(c Connection) Connection {
+	c.ID = ""
+	return c
+}
+
+// toMap converts a struct to its JSON fields, so they can be compared the
+// same way as node configs.
+func toMap(v interface{}) map[string]interface{} {
+	data, _ := json.Marshal(v)
+	var m map[string]interface{}
+	json.Unmarshal(data, &m)
+	return m
+}
@@ -3,10 +3,15 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kierank/pipes/events"
 	"github.com/kierank/pipes/nodes"
 	"github.com/kierank/pipes/store"
 )
@@ -41,9 +46,13 @@ type Position struct {
 
 type Settings struct {
 	Schedule    string       `json:"schedule,omitempty"`
+	Timezone    string       `json:"timezone,omitempty"`
 	Enabled     bool         `json:"enabled"`
-	Timeout     int          `json:"timeout,omitempty"`
+	Timeout     int          `json:"timeout,omitempty"` // seconds
 	RetryConfig *RetryConfig `json:"retryConfig,omitempty"`
+
+	// MaxConcurrency limits how many nodes of this pipe run at once
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
 }
 
 type RetryConfig struct {
@@ -51,120 +60,699 @@ type RetryConfig struct {
 	BackoffMs  int `json:"backoffMs"`
 }
 
+const (
+	defaultBackoff = 1 * time.Second
+	maxBackoff     = 5 * time.Minute
+
+	// executorLogID is the node_id used for execution logs that belong to
+	// the run as a whole rather than to a single node.
+	executorLogID = "executor"
+)
+
+// maxAttempts returns the total number of attempts, including the first.
+func (r *RetryConfig) maxAttempts() int {
+	if r == nil || r.MaxRetries <= 0 {
+		return 1
+	}
+	return r.MaxRetries + 1
+}
+
+// backoff returns the delay after the given failed attempt (1-based),
+// doubling from BackoffMs each time.
+func (r *RetryConfig) backoff(attempt int) time.Duration {
+	delay := defaultBackoff
+	if r != nil && r.BackoffMs > 0 {
+		delay = time.Duration(r.BackoffMs) * time.Millisecond
+	}
+
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// ParseSchedule parses the pipe's cron schedule. It returns nil if the pipe
+// has no schedule.
+func (s *Settings) ParseSchedule() (*CronSchedule, error) {
+	if s.Schedule == "" {
+		return nil, nil
+	}
+	return ParseCron(s.Schedule, s.Timezone)
+}
+
+const (
+	// defaultMaxConcurrency bounds how many nodes of a single execution run
+	// at once when the pipe doesn't set maxConcurrency.
+	defaultMaxConcurrency = 4
+
+	// defaultMaxWorkers bounds how many nodes run at once across all
+	// executions sharing an Executor.
+	defaultMaxWorkers = 8
+)
+
 type Executor struct {
 	db       *store.DB
 	registry *Registry
+	workers  chan struct{}
+	events   *events.Bus
 }
 
-func NewExecutor(db *store.DB) *Executor {
+// NewExecutor creates an executor that runs at most maxWorkers nodes at a
+// time across all of its executions. A non-positive maxWorkers uses the
+// default.
+func NewExecutor(db *store.DB, maxWorkers int) *Executor {
+	if maxWorkers <= 0 {
+		maxWorkers = defaultMaxWorkers
+	}
+
 	return &Executor{
 		db:       db,
 		registry: NewRegistry(),
+		workers:  make(chan struct{}, maxWorkers),
+		events:   events.NewBus(),
 	}
 }
 
+// Events returns the bus that execution progress is published on.
+func (e *Executor) Events() *events.Bus {
+	return e.events
+}
+
+// log writes an execution log entry and publishes it.
+func (e *Executor) log(executionID, nodeID, level, message string) {
+	e.db.LogExecution(executionID, nodeID, level, message)
+	e.events.Publish(events.Event{
+		Type:        events.TypeLog,
+		ExecutionID: executionID,
+		NodeID:      nodeID,
+		Level:       level,
+		Message:     message,
+	})
+}
+
+// logData writes an execution log entry with a JSON payload and publishes
+// it.
+func (e *Executor) logData(executionID, nodeID, level, message, data string) {
+	e.db.LogExecutionWithData(executionID, nodeID, level, message, data)
+	e.events.Publish(events.Event{
+		Type:        events.TypeLog,
+		ExecutionID: executionID,
+		NodeID:      nodeID,
+		Level:       level,
+		Message:     message,
+		Data:        json.RawMessage(data),
+	})
+}
+
+// publishStatus publishes an execution's status; anything but "running"
+// ends its event stream.
+func (e *Executor) publishStatus(executionID, status, message string, items *int) {
+	e.events.Publish(events.Event{
+		Type:        events.TypeStatus,
+		ExecutionID: executionID,
+		Status:      status,
+		Message:     message,
+		Items:       items,
+	})
+}
+
+// ExecuteOptions describe how an execution was triggered.
+type ExecuteOptions struct {
+	TriggerType string
+
+	// Payload is the data the run was triggered with (e.g. a webhook's JSON
+	// body), exposed to nodes through nodes.Context.
+	Payload interface{}
+
+	// Set for runs made by another pipe through RunPipe
+	call      *nodes.PipeCall
+	callStack []string
+
+	// Set for preview runs, which record nothing
+	preview *previewRun
+
+	// recorded holds the outputs replayed instead of running their nodes
+	recorded map[string]*nodeOutput
+}
+
 func (e *Executor) Execute(ctx context.Context, pipeID string, triggerType string) (string, error) {
+	return e.ExecuteWithOptions(ctx, pipeID, ExecuteOptions{TriggerType: triggerType})
+}
+
+func (e *Executor) ExecuteWithOptions(ctx context.Context, pipeID string, opts ExecuteOptions) (string, error) {
+	executionID, err := e.createExecution(pipeID, opts.TriggerType, "")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = e.run(ctx, executionID, pipeID, opts)
+	return executionID, err
+}
+
+// createExecution records a new running execution and returns its ID.
+// parentExecutionID is set for runs made by another pipe.
+func (e *Executor) createExecution(pipeID, triggerType, parentExecutionID string) (string, error) {
 	executionID := uuid.New().String()
-	startedAt := time.Now().Unix()
 
-	// Create execution record
-	if err := e.db.CreateExecution(executionID, pipeID, triggerType, startedAt); err != nil {
+	if err := e.db.CreateExecution(executionID, pipeID, triggerType, parentExecutionID, time.Now().Unix()); err != nil {
 		return "", fmt.Errorf("create execution: %w", err)
 	}
+	e.publishStatus(executionID, "running", "", nil)
 
-	// Fetch pipe configuration
-	pipe, err := e.db.GetPipe(pipeID)
-	if err != nil {
-		return "", fmt.Errorf("get pipe: %w", err)
-	}
+	return executionID, nil
+}
 
-	if pipe == nil {
-		return "", fmt.Errorf("pipe not found: %s", pipeID)
+// run executes a pipe for an execution created with createExecution and
+// records the outcome. If ctx was cancelled with ErrCancelled as its cause,
+// the execution is marked cancelled rather than failed.
+func (e *Executor) run(ctx context.Context, executionID, pipeID string, opts ExecuteOptions) (*pipelineResult, error) {
+	startedAt := time.Now().Unix()
+
+	pipe, config, err := e.loadPipe(pipeID)
+	if err == nil {
+		// Report every problem up front rather than failing on the first
+		// one mid-run
+		if errs := e.registry.Validate(config); errs != nil {
+			for _, verr := range errs {
+				nodeID := verr.NodeID
+				if nodeID == "" {
+					nodeID = executorLogID
+				}
+				e.log(executionID, nodeID, "error", verr.Message)
+			}
+			err = errs
+		}
+	}
+	if err != nil {
+		e.db.UpdateExecutionFailed(executionID, time.Now().Unix(), 0, err.Error())
+		e.publishStatus(executionID, "failed", err.Error(), nil)
+		return nil, err
 	}
 
-	var config PipeConfig
-	if err := json.Unmarshal([]byte(pipe.Config), &config); err != nil {
-		return "", fmt.Errorf("parse config: %w", err)
+	// Enforce the per-pipe deadline across every attempt and node
+	if config.Settings.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(config.Settings.Timeout)*time.Second)
+		defer cancel()
 	}
 
 	// Execute pipeline
-	itemCount, err := e.executePipeline(ctx, executionID, pipeID, &config)
+	result, attempts, err := e.executeWithRetry(ctx, executionID, pipe, config, &opts)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %ds: %w", config.Settings.Timeout, err)
+	}
 
 	completedAt := time.Now().Unix()
 	durationMs := (completedAt - startedAt) * 1000
 
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"attempts": attempts,
+	})
+	e.db.UpdateExecutionMetadata(executionID, string(metadata))
+
+	if err != nil && errors.Is(context.Cause(ctx), ErrCancelled) {
+		e.log(executionID, executorLogID, "warn", "Execution cancelled")
+		e.db.UpdateExecutionCancelled(executionID, completedAt, durationMs, context.Cause(ctx).Error())
+		e.publishStatus(executionID, "cancelled", context.Cause(ctx).Error(), nil)
+		return nil, context.Cause(ctx)
+	}
+
 	if err != nil {
 		e.db.UpdateExecutionFailed(executionID, completedAt, durationMs, err.Error())
-		return executionID, err
+		e.publishStatus(executionID, "failed", err.Error(), nil)
+		return nil, err
 	}
 
+	itemCount := len(result.final())
 	e.db.UpdateExecutionSuccess(executionID, completedAt, durationMs, itemCount)
-	return executionID, nil
+	e.publishStatus(executionID, "success", "", &itemCount)
+	return result, nil
+}
+
+func (e *Executor) loadPipe(pipeID string) (*store.Pipe, *PipeConfig, error) {
+	pipe, err := e.db.GetPipe(pipeID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get pipe: %w", err)
+	}
+
+	if pipe == nil {
+		return nil, nil, fmt.Errorf("pipe not found: %s", pipeID)
+	}
+
+	config, err := ParseConfig(pipe.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pipe, config, nil
+}
+
+// maxCallDepth limits how deeply pipes may call each other, counting the
+// top-level pipe.
+const maxCallDepth = 5
+
+// RunPipe runs a pipe as a child execution of the caller's, and returns
+// the output of its last node. The caller's owner must be allowed to load
+// the pipe, and the pipe can't already be running further up the chain of
+// calls. It must be called from a node's Execute.
+func (e *Executor) RunPipe(ctx context.Context, caller *nodes.Context, call nodes.PipeCall) ([]interface{}, error) {
+	stack := append(slices.Clone(caller.CallStack), call.PipeID)
+	if slices.Contains(caller.CallStack, call.PipeID) {
+		return nil, fmt.Errorf("recursive pipe call: %s", strings.Join(stack, " -> "))
+	}
+	if len(stack) > maxCallDepth {
+		return nil, fmt.Errorf("pipe calls nested more than %d deep: %s", maxCallDepth, strings.Join(stack, " -> "))
+	}
+
+	pipe, err := caller.LoadPipe(call.PipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	triggerType := call.TriggerType
+	if triggerType == "" {
+		triggerType = "sub-pipe"
+	}
+
+	opts := ExecuteOptions{
+		TriggerType: triggerType,
+		call:        &call,
+		callStack:   stack,
+	}
+
+	// Previews of the caller preview the pipe too, without an execution
+	var executionID string
+	if caller.Preview != nil {
+		opts.preview = &previewRun{Preview: caller.Preview}
+		caller.Log(caller.NodeID, "info", fmt.Sprintf("Previewing pipe %q", pipe.Name))
+	} else {
+		executionID, err = e.createExecution(pipe.ID, triggerType, caller.ExecutionID)
+		if err != nil {
+			return nil, err
+		}
+		e.log(caller.ExecutionID, caller.NodeID, "info", fmt.Sprintf("Running pipe %q as execution %s", pipe.Name, executionID))
+	}
+
+	// The calling node holds a worker slot while it waits. Lend it to the
+	// child's nodes, so nested calls can't take every slot and then wait
+	// on each other forever.
+	<-e.workers
+	defer func() { e.workers <- struct{}{} }()
+
+	var result *pipelineResult
+	if opts.preview != nil {
+		var config *PipeConfig
+		if config, err = ParseConfig(pipe.Config); err == nil {
+			result, err = e.preview(ctx, pipe, config, opts)
+		}
+	} else {
+		result, err = e.run(ctx, executionID, pipe.ID, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pipe %q: %w", pipe.Name, err)
+	}
+
+	return result.final(), nil
 }
 
-func (e *Executor) executePipeline(ctx context.Context, executionID, pipeID string, config *PipeConfig) (int, error) {
-	// Topological sort to determine execution order
+// executeWithRetry runs the pipeline, retrying the whole run with
+// exponential backoff according to the pipe's RetryConfig. It returns the
+// number of attempts made.
+func (e *Executor) executeWithRetry(ctx context.Context, executionID string, pipe *store.Pipe, config *PipeConfig, opts *ExecuteOptions) (*pipelineResult, int, error) {
+	retry := config.Settings.RetryConfig
+	maxAttempts := retry.maxAttempts()
+
+	for attempt := 1; ; attempt++ {
+		if maxAttempts > 1 {
+			e.log(executionID, executorLogID, "info", fmt.Sprintf("Attempt %d/%d", attempt, maxAttempts))
+		}
+
+		result, err := e.executePipeline(ctx, executionID, pipe, config, opts)
+		if err == nil {
+			return result, attempt, nil
+		}
+
+		if ctx.Err() != nil || attempt >= maxAttempts {
+			return nil, attempt, err
+		}
+
+		delay := retry.backoff(attempt)
+		e.log(executionID, executorLogID, "warn", fmt.Sprintf("Attempt %d/%d failed: %v (retrying in %s)", attempt, maxAttempts, err, delay))
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, attempt, err
+		}
+	}
+}
+
+// executePipeline runs the nodes of the DAG, starting each node as soon as
+// all of its upstream nodes have finished. Independent branches run
+// concurrently, bounded by the pipe's maxConcurrency and the executor's
+// global worker limit. Only this goroutine touches nodeResults, so node
+// outputs and the final result don't depend on scheduling.
+func (e *Executor) executePipeline(ctx context.Context, executionID string, pipe *store.Pipe, config *PipeConfig, opts *ExecuteOptions) (*pipelineResult, error) {
+	// Topological sort to determine a deterministic dispatch order
 	order, err := topologicalSort(config.Nodes, config.Connections)
 	if err != nil {
-		return 0, fmt.Errorf("topological sort: %w", err)
+		return nil, fmt.Errorf("topological sort: %w", err)
+	}
+
+	if len(order) == 0 {
+		return &pipelineResult{}, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limit := config.Settings.MaxConcurrency
+	if limit <= 0 {
+		limit = defaultMaxConcurrency
+	}
+
+	position := make(map[string]int, len(order))
+	for i, id := range order {
+		position[id] = i
 	}
 
-	nodeResults := make(map[string][]interface{})
-	execCtx := nodes.NewContext(executionID, pipeID, e.db)
+	// Count unfinished upstream nodes for each node
+	pending := make(map[string]int)
+	dependents := make(map[string][]string)
+	for _, c := range config.Connections {
+		pending[c.Target]++
+		dependents[c.Source] = append(dependents[c.Source], c.Target)
+	}
+
+	var ready []string
+	for _, id := range order {
+		if pending[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	type nodeResult struct {
+		node     *Node
+		output   *nodeOutput
+		err      error
+		duration time.Duration
+	}
+
+	results := make(chan nodeResult)
+	nodeResults := make(map[string]*nodeOutput)
+	execCtx := nodes.NewContext(executionID, pipe.ID, e.db)
+	execCtx.TriggerType = opts.TriggerType
+	execCtx.TriggerPayload = opts.Payload
+	if opts.preview != nil {
+		execCtx.Preview = opts.preview.Preview
+	} else {
+		execCtx.Events = e.events
+	}
+	execCtx.OwnerID = pipe.UserID
+	execCtx.Pipes = e
+	execCtx.Call = opts.call
+	execCtx.CallStack = opts.callStack
+	if len(execCtx.CallStack) == 0 {
+		execCtx.CallStack = []string{pipe.ID}
+	}
+	running := 0
+	var firstErr error
+
+	for {
+		// Start ready nodes up to the per-execution limit
+		for firstErr == nil && len(ready) > 0 && running < limit {
+			node := findNode(config.Nodes, ready[0])
+			ready = ready[1:]
+
+			// Get node implementation
+			nodeImpl, err := e.registry.Get(node.Type)
+			if err != nil {
+				firstErr = fmt.Errorf("get node type %s: %w", node.Type, err)
+				cancel()
+				break
+			}
+
+			// Gather inputs from connected nodes
+			inputs := e.gatherInputs(node.ID, nodeImpl, config.Connections, nodeResults)
+
+			running++
+			execCtx.Events.Publish(events.Event{Type: events.TypeNodeStart, ExecutionID: executionID, NodeID: node.ID})
+			go func() {
+				start := time.Now()
+				if output, ok := opts.recorded[node.ID]; ok {
+					execCtx.ForNode(node.ID).Log(node.ID, "info", "Replaying recorded output")
+					results <- nodeResult{node: node, output: output}
+					return
+				}
+				output, err := e.runNode(ctx, nodeImpl, node, inputs, execCtx.ForNode(node.ID), config.Settings.RetryConfig)
+				results <- nodeResult{node: node, output: output, err: err, duration: time.Since(start)}
+			}()
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		if opts.preview != nil {
+			opts.preview.record(res.node, res.output, res.err, res.duration)
+		} else {
+			e.publishNodeFinish(executionID, res.node.ID, res.output, res.err, res.duration)
+		}
 
-	for _, nodeID := range order {
-		node := findNode(config.Nodes, nodeID)
-		if node == nil {
+		if res.err != nil {
+			if firstErr == nil {
+				execCtx.ForNode(res.node.ID).Log(res.node.ID, "error", fmt.Sprintf("Execution failed: %v", res.err))
+				firstErr = fmt.Errorf("node %s (%s): %w", res.node.ID, res.node.Type, res.err)
+				cancel()
+			}
 			continue
 		}
 
-		// Get node implementation
-		nodeImpl, err := e.registry.Get(node.Type)
-		if err != nil {
-			return 0, fmt.Errorf("get node type %s: %w", node.Type, err)
+		nodeResults[res.node.ID] = res.output
+
+		// Log output data
+		if opts.preview == nil {
+			e.logOutput(executionID, res.node.ID, res.output)
+		}
+
+		// Queue downstream nodes whose inputs are now complete, keeping
+		// the ready list in topological order
+		for _, dep := range dependents[res.node.ID] {
+			pending[dep]--
+			if pending[dep] == 0 {
+				ready = append(ready, dep)
+				sort.Slice(ready, func(i, j int) bool { return position[ready[i]] < position[ready[j]] })
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := execCtx.CommitState(); err != nil {
+		return nil, fmt.Errorf("commit node state: %w", err)
+	}
+
+	return &pipelineResult{outputs: nodeResults, last: order[len(order)-1]}, nil
+}
+
+// pipelineResult holds the node outputs of a successful pipeline run.
+type pipelineResult struct {
+	outputs map[string]*nodeOutput
+	last    string
+}
+
+// final returns the output of the last node in topological order, which is
+// the pipe's result.
+func (r *pipelineResult) final() []interface{} {
+	if r.last == "" {
+		return []interface{}{}
+	}
+	return r.outputs[r.last].port("")
+}
+
+func (e *Executor) publishNodeFinish(executionID, nodeID string, output *nodeOutput, err error, duration time.Duration) {
+	ms := duration.Milliseconds()
+	event := events.Event{
+		Type:        events.TypeNodeFinish,
+		ExecutionID: executionID,
+		NodeID:      nodeID,
+		Status:      "success",
+		DurationMs:  &ms,
+	}
+
+	if err != nil {
+		event.Status = "failed"
+		event.Message = err.Error()
+	} else {
+		items := output.count()
+		event.Items = &items
+	}
+
+	e.events.Publish(event)
+}
+
+// runNode executes a node once a global worker slot is free.
+func (e *Executor) runNode(ctx context.Context, nodeImpl nodes.Node, node *Node, inputs [][]interface{}, execCtx *nodes.Context, retry *RetryConfig) (*nodeOutput, error) {
+	select {
+	case e.workers <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-e.workers }()
+
+	return e.executeNode(ctx, nodeImpl, node, inputs, execCtx, retry)
+}
+
+// executeNode runs a single node. Source nodes that fail with a transient
+// error are retried with exponential backoff.
+func (e *Executor) executeNode(ctx context.Context, nodeImpl nodes.Node, node *Node, inputs [][]interface{}, execCtx *nodes.Context, retry *RetryConfig) (*nodeOutput, error) {
+	maxAttempts := 1
+	if nodeImpl.Category() == "source" {
+		maxAttempts = retry.maxAttempts()
+	}
+
+	for attempt := 1; ; attempt++ {
+		output, err := callNode(ctx, nodeImpl, node, inputs, execCtx)
+		if err == nil || !nodes.IsTransient(err) || ctx.Err() != nil || attempt >= maxAttempts {
+			return output, err
 		}
 
-		// Gather inputs from connected nodes
-		inputs := e.gatherInputs(nodeID, config.Connections, nodeResults)
+		delay := retry.backoff(attempt)
+		execCtx.Log(node.ID, "warn", fmt.Sprintf("Attempt %d/%d failed: %v (retrying in %s)", attempt, maxAttempts, err, delay))
 
-		// Execute node
-		output, err := nodeImpl.Execute(ctx, node.Config, inputs, execCtx)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// callNode executes a node and collects its output by port.
+func callNode(ctx context.Context, nodeImpl nodes.Node, node *Node, inputs [][]interface{}, execCtx *nodes.Context) (*nodeOutput, error) {
+	multi, ok := nodeImpl.(nodes.MultiOutputNode)
+	if !ok {
+		items, err := nodeImpl.Execute(ctx, node.Config, inputs, execCtx)
 		if err != nil {
-			e.db.LogExecution(executionID, nodeID, "error", fmt.Sprintf("Execution failed: %v", err))
-			return 0, fmt.Errorf("node %s (%s): %w", nodeID, node.Type, err)
+			return nil, err
 		}
+		return &nodeOutput{ports: map[string][]interface{}{"": items}}, nil
+	}
 
-		nodeResults[nodeID] = output
-		
-		// Log output data
-		outputJSON, _ := json.Marshal(output)
-		e.db.LogExecutionWithData(executionID, nodeID, "data", fmt.Sprintf("%d items", len(output)), string(outputJSON))
+	ports, err := multi.ExecutePorts(ctx, node.Config, inputs, execCtx)
+	if err != nil {
+		return nil, err
 	}
 
-	// Return item count from last node
-	if len(order) == 0 {
-		return 0, nil
+	output := &nodeOutput{ports: ports, names: multi.OutputPorts(node.Config)}
+	if len(output.names) > 0 {
+		output.defaultPort = output.names[0]
+	}
+	return output, nil
+}
+
+// nodeOutput holds the items a node emitted on each of its output ports.
+// Nodes with a single output use the empty port name.
+type nodeOutput struct {
+	ports       map[string][]interface{}
+	names       []string
+	defaultPort string
+}
+
+// count returns the number of items on all ports.
+func (o *nodeOutput) count() int {
+	n := 0
+	for _, items := range o.ports {
+		n += len(items)
 	}
+	return n
+}
 
-	lastNodeID := order[len(order)-1]
-	finalOutput := nodeResults[lastNodeID]
-	return len(finalOutput), nil
+// port returns the items on the port a connection's sourceHandle refers
+// to. An empty handle selects the default port, and single-output nodes
+// ignore the handle.
+func (o *nodeOutput) port(handle string) []interface{} {
+	if handle == "" || (o.defaultPort == "" && len(o.ports) == 1) {
+		return o.ports[o.defaultPort]
+	}
+	return o.ports[handle]
 }
 
-func (e *Executor) gatherInputs(nodeID string, connections []Connection, nodeResults map[string][]interface{}) [][]interface{} {
-	var inputs [][]interface{}
+// logOutput records a node's output in the execution logs. Multi-output
+// nodes log an object keyed by port name.
+func (e *Executor) logOutput(executionID, nodeID string, output *nodeOutput) {
+	if output.defaultPort == "" {
+		items := output.port("")
+		outputJSON, _ := json.Marshal(items)
+		e.logData(executionID, nodeID, "data", fmt.Sprintf("%d items", len(items)), string(outputJSON))
+		return
+	}
 
+	counts := make([]string, 0, len(output.names))
+	for _, name := range output.names {
+		counts = append(counts, fmt.Sprintf("%d %s", len(output.ports[name]), name))
+	}
+
+	outputJSON, _ := json.Marshal(output.ports)
+	e.logData(executionID, nodeID, "data", strings.Join(counts, ", "), string(outputJSON))
+}
+
+// gatherInputs collects a node's inputs from its upstream nodes. Nodes with
+// named input ports get one input per port in port order; other nodes get
+// one input per connection, ordered by target handle and then by position
+// in the config.
+func (e *Executor) gatherInputs(nodeID string, nodeImpl nodes.Node, connections []Connection, nodeResults map[string]*nodeOutput) [][]interface{} {
+	var incoming []Connection
 	for _, conn := range connections {
 		if conn.Target == nodeID {
-			if result, ok := nodeResults[conn.Source]; ok {
-				inputs = append(inputs, result)
+			if _, ok := nodeResults[conn.Source]; ok {
+				incoming = append(incoming, conn)
+			}
+		}
+	}
+
+	if ports := nodes.InputPorts(nodeImpl); len(ports) > 0 {
+		inputs := make([][]interface{}, len(ports))
+		for _, conn := range incoming {
+			idx := portIndex(ports, conn.TargetHandle)
+			if idx < 0 {
+				continue
 			}
+			inputs[idx] = append(inputs[idx], nodeResults[conn.Source].port(conn.SourceHandle)...)
 		}
+		return inputs
+	}
+
+	sort.SliceStable(incoming, func(i, j int) bool {
+		return incoming[i].TargetHandle < incoming[j].TargetHandle
+	})
+
+	inputs := make([][]interface{}, 0, len(incoming))
+	for _, conn := range incoming {
+		inputs = append(inputs, nodeResults[conn.Source].port(conn.SourceHandle))
 	}
 
 	return inputs
 }
 
+// portIndex returns the index of a handle in a port list. An empty handle
+// refers to the first port.
+func portIndex(ports []string, handle string) int {
+	if handle == "" {
+		return 0
+	}
+	for i, p := range ports {
+		if p == handle {
+			return i
+		}
+	}
+	return -1
+}
+
 func topologicalSort(nodes []Node, connections []Connection) ([]string, error) {
 	// Kahn's algorithm for topological sorting
 	inDegree := make(map[string]int)
@@ -182,11 +770,12 @@ func topologicalSort(nodes []Node, connections []Connection) ([]string, error) {
 		inDegree[c.Target]++
 	}
 
-	// Find sources (nodes with no incoming edges)
+	// Find sources (nodes with no incoming edges), in config order so the
+	// result is deterministic
 	queue := []string{}
-	for id, degree := range inDegree {
-		if degree == 0 {
-			queue = append(queue, id)
+	for _, n := range nodes {
+		if inDegree[n.ID] == 0 {
+			queue = append(queue, n.ID)
 		}
 	}
 
@@ -219,3 +808,16 @@ func findNode(nodes []Node, id string) *Node {
 	}
 	return nil
 }
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
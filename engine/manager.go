@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/kierank/pipes/events"
+	"github.com/kierank/pipes/store"
+)
+
+var (
+	// ErrCancelled is the cancellation cause for an execution stopped on
+	// purpose. Executions whose context is cancelled with a cause wrapping
+	// it are recorded as cancelled rather than failed.
+	ErrCancelled = errors.New("execution cancelled")
+
+	// ErrShutdown is the cause for executions interrupted by a server
+	// shutdown.
+	ErrShutdown = fmt.Errorf("%w: server shutting down", ErrCancelled)
+)
+
+// Manager runs executions in the background and keeps track of running ones
+// so they can be cancelled. Executions started through the manager are not
+// tied to the request that started them.
+type Manager struct {
+	executor *Executor
+
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc
+	wg      sync.WaitGroup
+
+	baseCtx    context.Context
+	cancelBase context.CancelCauseFunc
+}
+
+func NewManager(executor *Executor) *Manager {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &Manager{
+		executor:   executor,
+		running:    make(map[string]context.CancelCauseFunc),
+		baseCtx:    ctx,
+		cancelBase: cancel,
+	}
+}
+
+// Start creates an execution and runs it in the background, returning its
+// ID immediately.
+func (m *Manager) Start(pipeID string, opts ExecuteOptions) (string, error) {
+	executionID, err := m.executor.createExecution(pipeID, opts.TriggerType, "")
+	if err != nil {
+		return "", err
+	}
+
+	ctx := m.register(m.baseCtx, executionID)
+	go func() {
+		defer m.finish(executionID)
+		m.executor.run(ctx, executionID, pipeID, opts)
+	}()
+
+	return executionID, nil
+}
+
+// Run executes a pipe and waits for it to finish. The execution can still
+// be cancelled through the manager, and also stops if ctx is cancelled.
+func (m *Manager) Run(ctx context.Context, pipeID string, opts ExecuteOptions) (string, error) {
+	executionID, err := m.executor.createExecution(pipeID, opts.TriggerType, "")
+	if err != nil {
+		return "", err
+	}
+
+	// Stop on shutdown as well as when the caller gives up
+	runCtx := m.register(ctx, executionID)
+	stop := context.AfterFunc(m.baseCtx, func() {
+		m.Cancel(executionID, context.Cause(m.baseCtx))
+	})
+	defer stop()
+	defer m.finish(executionID)
+
+	_, err = m.executor.run(runCtx, executionID, pipeID, opts)
+	return executionID, err
+}
+
+// Preview previews a node of a pipe (see Executor.Preview) and waits for it
+// to finish. It stops if ctx is cancelled or the manager shuts down.
+func (m *Manager) Preview(ctx context.Context, pipe *store.Pipe, nodeID string, opts PreviewOptions) (*PreviewResult, error) {
+	ctx, stop := m.foreground(ctx)
+	defer stop()
+
+	return m.executor.Preview(ctx, pipe, nodeID, opts)
+}
+
+// Replay replays an execution of a pipe (see Executor.Replay) and waits
+// for it to finish. It stops if ctx is cancelled or the manager shuts down.
+func (m *Manager) Replay(ctx context.Context, pipe *store.Pipe, executionID string, opts ReplayOptions) (*ReplayResult, error) {
+	ctx, stop := m.foreground(ctx)
+	defer stop()
+
+	return m.executor.Replay(ctx, pipe, executionID, opts)
+}
+
+// foreground returns a context for work the caller waits on that isn't an
+// execution, which is also cancelled when the manager shuts down.
+func (m *Manager) foreground(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(m.baseCtx, func() {
+		cancel(context.Cause(m.baseCtx))
+	})
+	return ctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+// Cancel stops a running execution. It reports whether the execution was
+// running.
+func (m *Manager) Cancel(executionID string, cause error) bool {
+	m.mu.Lock()
+	cancel, ok := m.running[executionID]
+	m.mu.Unlock()
+
+	if ok {
+		cancel(cause)
+	}
+	return ok
+}
+
+// Events returns the bus that execution progress is published on.
+func (m *Manager) Events() *events.Bus {
+	return m.executor.Events()
+}
+
+// IsRunning reports whether an execution is running in this process.
+func (m *Manager) IsRunning(executionID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.running[executionID]
+	return ok
+}
+
+// Shutdown cancels all running executions and waits for them to record
+// their status, or for ctx to expire.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.cancelBase(ErrShutdown)
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) register(parent context.Context, executionID string) context.Context {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	m.mu.Lock()
+	m.running[executionID] = cancel
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	return ctx
+}
+
+func (m *Manager) finish(executionID string) {
+	m.mu.Lock()
+	cancel := m.running[executionID]
+	delete(m.running, executionID)
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel(nil)
+	}
+	m.wg.Done()
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ConfigVersion is the version of the pipe configs this build writes.
+// Bump it whenever a node's config changes in a way older configs need
+// upgrading for, and add the migration below.
+const ConfigVersion = 2
+
+// configMigrations upgrade a pipe config by one version each:
+// configMigrations[0] takes version 1 to 2, and so on. A migration gets
+// the whole config, so besides renaming config keys it can replace a node
+// with several and rewire their connections.
+var configMigrations = []func(config *PipeConfig) error{
+	migrateFilterCondition,
+}
+
+// ParseConfig decodes a stored pipe config, upgrading it to the current
+// version.
+func ParseConfig(configJSON string) (*PipeConfig, error) {
+	var config PipeConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	if _, err := config.Migrate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// Migrate upgrades the config to the current version in place, and
+// reports whether it was changed. Configs without a version are taken to
+// be version 1.
+func (c *PipeConfig) Migrate() (bool, error) {
+	version := 1
+	if c.Version != "" {
+		v, err := strconv.Atoi(c.Version)
+		if err != nil || v < 1 {
+			return false, fmt.Errorf("invalid config version %q", c.Version)
+		}
+		version = v
+	}
+
+	if version > ConfigVersion {
+		return false, fmt.Errorf("config version %d is newer than this version of pipes supports (%d)", version, ConfigVersion)
+	}
+
+	migrated := false
+	for ; version < ConfigVersion; version++ {
+		if err := configMigrations[version-1](c); err != nil {
+			return false, fmt.Errorf("migrate config from version %d: %w", version, err)
+		}
+		migrated = true
+	}
+
+	c.Version = strconv.Itoa(ConfigVersion)
+	return migrated, nil
+}
+
+// eachNode calls fn with the config of every node of the given type,
+// stopping at the first error.
+func (c *PipeConfig) eachNode(nodeType string, fn func(config map[string]interface{}) error) error {
+	for i := range c.Nodes {
+		if c.Nodes[i].Type != nodeType {
+			continue
+		}
+		if c.Nodes[i].Config == nil {
+			c.Nodes[i].Config = make(map[string]interface{})
+		}
+		if err := fn(c.Nodes[i].Config); err != nil {
+			return fmt.Errorf("node %s: %w", c.Nodes[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// migrateFilterCondition moves the Filter's single field/operator/value
+// condition into its condition list, which replaced it. The old condition
+// compared the field printed as text, so it's written as regexes on the
+// field's text, which keep the old matching for arrays, numbers and
+// missing fields. Conditions the list can't hold, such as regexes with
+// line breaks, are refused rather than changed.
+func migrateFilterCondition(c *PipeConfig) error {
+	return c.eachNode("filter", func(config map[string]interface{}) error {
+		field, _ := config["field"].(string)
+		operator, _ := config["operator"].(string)
+		value, _ := config["value"].(string)
+
+		// The single condition was ignored in favour of these
+		if mode, _ := config["mode"].(string); mode == "expression" {
+			deleteFilterCondition(config)
+			return nil
+		}
+		if conditions, _ := config["conditions"].(string); strings.TrimSpace(conditions) != "" {
+			deleteFilterCondition(config)
+			return nil
+		}
+
+		// Without a field or a known operator everything matched, the same
+		// as having no conditions
+		switch operator {
+		case "contains", "equals", "not-equals", "regex":
+		default:
+			deleteFilterCondition(config)
+			return nil
+		}
+		if field == "" {
+			deleteFilterCondition(config)
+			return nil
+		}
+
+		conditions, err := legacyConditions(field, operator, value)
+		if err != nil {
+			return err
+		}
+
+		deleteFilterCondition(config)
+		config["conditions"] = conditions
+		if operator != "contains" {
+			config["case"] = "sensitive"
+		}
+		return nil
+	})
+}
+
+func deleteFilterCondition(config map[string]interface{}) {
+	delete(config, "field")
+	delete(config, "operator")
+	delete(config, "value")
+}
+
+// legacyNil is how the old Filter printed a missing field, which its
+// conditions then compared like any other text.
+const legacyNil = "<nil>"
+
+// legacyConditions writes the old Filter's condition as a condition list
+// that keeps the same items. Where the old condition matched "<nil>",
+// items without the field are matched too. The list starts with a comment
+// holding the original condition.
+//
+// Numbers the old Filter printed in exponent form, such as 1e+06, are
+// compared as written out in full, since the condition list has no
+// other way to print them.
+func legacyConditions(field, operator, value string) (string, error) {
+	if strings.ContainsAny(field, " \t\r\n") || strings.HasPrefix(field, "#") {
+		return "", fmt.Errorf("filter field %q can't be written as a condition", field)
+	}
+
+	lines := []string{fmt.Sprintf("# Was: %s %s %s", field, operator, strconv.Quote(value))}
+	literal := strings.ReplaceAll(regexp.QuoteMeta(value), "\n", `\n`)
+
+	switch operator {
+	case "contains":
+		lines = append(lines, conditionLine(field, "regex", literal))
+		if strings.Contains(legacyNil, strings.ToLower(value)) {
+			lines = append(lines, "or", field+" not-exists")
+		}
+
+	case "equals":
+		lines = append(lines, conditionLine(field, "regex", "^"+literal+"$"))
+		if value == legacyNil {
+			lines = append(lines, "or", field+" not-exists")
+		}
+
+	case "not-equals":
+		lines = append(lines, conditionLine(field, "not-regex", "^"+literal+"$"))
+		if value == legacyNil {
+			lines = append(lines, field+" exists")
+		}
+
+	case "regex":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			// The old Filter matched nothing with an invalid regex
+			lines = append(lines, "# The regex is invalid, so nothing matches", field+" exists", field+" not-exists")
+			break
+		}
+		if strings.Contains(value, "\n") {
+			return "", fmt.Errorf("filter regex %q has a line break, which conditions can't hold", value)
+		}
+		lines = append(lines, conditionLine(field, "regex", value))
+		if re.MatchString(legacyNil) {
+			lines = append(lines, "or", field+" not-exists")
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// conditionLine writes a condition as a line of a Filter's condition list.
+// The value is quoted as is: the list only strips the outer quotes, and
+// has no escapes.
+func conditionLine(field, operator, value string) string {
+	return fmt.Sprintf(`%s %s "%s"`, field, operator, value)
+}
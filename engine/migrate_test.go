@@ -0,0 +1,305 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kierank/pipes/nodes"
+	"github.com/kierank/pipes/nodes/transforms"
+)
+
+func filterConfig(version string, config map[string]interface{}) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"version":     version,
+		"nodes":       []interface{}{map[string]interface{}{"id": "f", "type": "filter", "config": config}},
+		"connections": []interface{}{},
+	})
+	return string(data)
+}
+
+func TestMigrationsCoverEveryVersion(t *testing.T) {
+	if len(configMigrations) != ConfigVersion-1 {
+		t.Fatalf("%d migrations for config version %d", len(configMigrations), ConfigVersion)
+	}
+}
+
+// Version 1 to 2: migrateFilterCondition
+func TestMigrateFilterCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		config  map[string]interface{}
+		want    map[string]interface{}
+	}{
+		{
+			name:    "contains stays case-insensitive",
+			version: "",
+			config:  map[string]interface{}{"field": "title", "operator": "contains", "value": "go"},
+			want:    map[string]interface{}{"conditions": "# Was: title contains \"go\"\ntitle regex \"go\""},
+		},
+		{
+			name:    "contains a substring of <nil>",
+			version: "1",
+			config:  map[string]interface{}{"field": "title", "operator": "contains", "value": "NIL"},
+			want:    map[string]interface{}{"conditions": "# Was: title contains \"NIL\"\ntitle regex \"NIL\"\nor\ntitle not-exists"},
+		},
+		{
+			name:    "equals matches case",
+			version: "1",
+			config:  map[string]interface{}{"field": "author.name", "operator": "equals", "value": "Ann (ed.)"},
+			want:    map[string]interface{}{"conditions": "# Was: author.name equals \"Ann (ed.)\"\nauthor.name regex \"^Ann \\(ed\\.\\)$\"", "case": "sensitive"},
+		},
+		{
+			name:    "not-equals",
+			version: "1",
+			config:  map[string]interface{}{"field": "status", "operator": "not-equals", "value": ""},
+			want:    map[string]interface{}{"conditions": "# Was: status not-equals \"\"\nstatus not-regex \"^$\"", "case": "sensitive"},
+		},
+		{
+			name:    "not-equals <nil>",
+			version: "1",
+			config:  map[string]interface{}{"field": "status", "operator": "not-equals", "value": "<nil>"},
+			want:    map[string]interface{}{"conditions": "# Was: status not-equals \"<nil>\"\nstatus not-regex \"^<nil>$\"\nstatus exists", "case": "sensitive"},
+		},
+		{
+			name:    "regex",
+			version: "1",
+			config:  map[string]interface{}{"field": "link", "operator": "regex", "value": `^https://.*\.dev/`},
+			want:    map[string]interface{}{"conditions": "# Was: link regex \"^https://.*\\\\.dev/\"\nlink regex \"^https://.*\\.dev/\"", "case": "sensitive"},
+		},
+		{
+			name:    "invalid regex",
+			version: "1",
+			config:  map[string]interface{}{"field": "link", "operator": "regex", "value": "("},
+			want:    map[string]interface{}{"conditions": "# Was: link regex \"(\"\n# The regex is invalid, so nothing matches\nlink exists\nlink not-exists", "case": "sensitive"},
+		},
+		{
+			name:    "no field",
+			version: "1",
+			config:  map[string]interface{}{"field": "", "operator": "contains", "value": "go"},
+			want:    map[string]interface{}{},
+		},
+		{
+			name:    "unknown operator",
+			version: "1",
+			config:  map[string]interface{}{"field": "title", "operator": "sounds-like", "value": "go"},
+			want:    map[string]interface{}{},
+		},
+		{
+			name:    "conditions take precedence",
+			version: "1",
+			config:  map[string]interface{}{"field": "title", "operator": "contains", "value": "go", "conditions": "score > 5"},
+			want:    map[string]interface{}{"conditions": "score > 5"},
+		},
+		{
+			name:    "expression mode takes precedence",
+			version: "1",
+			config:  map[string]interface{}{"field": "title", "operator": "contains", "value": "go", "mode": "expression", "expression": "true"},
+			want:    map[string]interface{}{"mode": "expression", "expression": "true"},
+		},
+		{
+			name:    "current version is left alone",
+			version: "2",
+			config:  map[string]interface{}{"conditions": `title contains "go"`},
+			want:    map[string]interface{}{"conditions": `title contains "go"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseConfig(filterConfig(tt.version, tt.config))
+			if err != nil {
+				t.Fatalf("ParseConfig: %v", err)
+			}
+			if config.Version != "2" {
+				t.Errorf("version = %q, want 2", config.Version)
+			}
+			if got := config.Nodes[0].Config; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// legacyMatch is how the Filter matched before version 2.
+func legacyMatch(item interface{}, field, operator, value string) bool {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	var fieldValue interface{} = itemMap
+	for _, part := range strings.Split(field, ".") {
+		m, _ := fieldValue.(map[string]interface{})
+		fieldValue = m[part]
+	}
+	fieldStr := fmt.Sprintf("%v", fieldValue)
+
+	switch operator {
+	case "contains":
+		return strings.Contains(strings.ToLower(fieldStr), strings.ToLower(value))
+	case "equals":
+		return fieldStr == value
+	case "not-equals":
+		return fieldStr != value
+	case "regex":
+		matched, _ := regexp.MatchString(value, fieldStr)
+		return matched
+	}
+	return true
+}
+
+// Migrated conditions must keep the items the old condition kept, whatever
+// the field holds, including nothing at all.
+func TestMigrateFilterConditionRoundTrips(t *testing.T) {
+	fields := []interface{}{
+		"go", "GO", "Go 1.24", " padded ", `"quoted"`, `'single'`, `it's "half`, `"`,
+		"tab\tand\\backslash", "# not a comment", "two\nlines", "<nil>", "nil", "", "a.b*c", "[golang news]",
+		float64(3), float64(-2.5), float64(0), true, false,
+		[]interface{}{"golang", "news"},
+		[]interface{}{},
+		[]interface{}{float64(1), "go"},
+		map[string]interface{}{"name": "go"},
+		nil,
+	}
+	values := []string{
+		"go", "Go", " padded ", `"quoted"`, `'single'`, `it's "half`, `"`, "tab\tand\\backslash",
+		"# not a comment", "two\nlines", "", "<nil>", "nil", "NIL", "<", "a.b*c", "3", "-2.5", "true", "false",
+		"[golang news]", "[]", "golang", "map[name:go]",
+	}
+	regexes := []string{
+		".*", "^$", "^go", "go$", "(?i)GO", `\d`, "^<nil>$", "nil", "^\\[", "^[^a-z]+$", `tab\t`, "two\nlines",
+		"^(true|3)$", "map", "(", "[", "a{2,1}",
+	}
+
+	var items []interface{}
+	for _, v := range fields {
+		items = append(items,
+			map[string]interface{}{"title": v},
+			map[string]interface{}{"meta": map[string]interface{}{"title": v}},
+		)
+	}
+	items = append(items, map[string]interface{}{}, map[string]interface{}{"meta": "flat"})
+
+	var cases [][3]string
+	for _, field := range []string{"title", "meta.title"} {
+		for _, operator := range []string{"contains", "equals", "not-equals"} {
+			for _, value := range values {
+				cases = append(cases, [3]string{field, operator, value})
+			}
+		}
+		for _, value := range regexes {
+			cases = append(cases, [3]string{field, "regex", value})
+		}
+	}
+
+	filter := &transforms.FilterNode{}
+	for _, c := range cases {
+		field, operator, value := c[0], c[1], c[2]
+
+		config, err := ParseConfig(filterConfig("1", map[string]interface{}{"field": field, "operator": operator, "value": value}))
+		if err != nil {
+			// Only regexes with line breaks are refused
+			if operator == "regex" && strings.Contains(value, "\n") {
+				continue
+			}
+			t.Fatalf("%s %s %q: %v", field, operator, value, err)
+		}
+		nodeConfig := config.Nodes[0].Config
+		if err := filter.ValidateConfig(nodeConfig); err != nil {
+			t.Fatalf("%s %s %q: migrated config isn't valid: %v (conditions %q)", field, operator, value, err, nodeConfig["conditions"])
+		}
+
+		ports, err := filter.ExecutePorts(context.Background(), nodeConfig, [][]interface{}{items}, &nodes.Context{Preview: &nodes.Preview{}})
+		if err != nil {
+			t.Fatalf("%s %s %q: %v (conditions %q)", field, operator, value, err, nodeConfig["conditions"])
+		}
+
+		want := []interface{}{}
+		for _, item := range items {
+			if legacyMatch(item, field, operator, value) {
+				want = append(want, item)
+			}
+		}
+		if !reflect.DeepEqual(ports["matched"], want) {
+			t.Errorf("%s %s %q (conditions %q):\nmatched %v\nwant    %v", field, operator, value, nodeConfig["conditions"], ports["matched"], want)
+		}
+	}
+}
+
+func TestMigrateFilterConditionRefusesUnwritableConditions(t *testing.T) {
+	for _, config := range []map[string]interface{}{
+		{"field": "title", "operator": "regex", "value": "two\nlines"},
+		{"field": "the title", "operator": "equals", "value": "go"},
+		{"field": "#tag", "operator": "equals", "value": "go"},
+	} {
+		if _, err := ParseConfig(filterConfig("1", config)); err == nil {
+			t.Errorf("ParseConfig(%v) succeeded, want error", config)
+		}
+	}
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	config, err := ParseConfig(filterConfig("1", map[string]interface{}{"field": "title", "operator": "equals", "value": "go"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := json.Marshal(config)
+
+	migrated, err := config.Migrate()
+	if err != nil || migrated {
+		t.Fatalf("second Migrate = %v, %v; want false, nil", migrated, err)
+	}
+
+	again, err := ParseConfig(string(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := json.Marshal(again)
+	if string(first) != string(second) {
+		t.Errorf("config changed on reload:\n%s\n%s", first, second)
+	}
+}
+
+func TestMigrateVersions(t *testing.T) {
+	tests := []struct {
+		version  string
+		migrated bool
+		wantErr  bool
+	}{
+		{version: "", migrated: true},
+		{version: "1", migrated: true},
+		{version: "2"},
+		{version: "3", wantErr: true},
+		{version: "99", wantErr: true},
+		{version: "0", wantErr: true},
+		{version: "-1", wantErr: true},
+		{version: "v2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		config := &PipeConfig{Version: tt.version}
+		migrated, err := config.Migrate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Migrate(version %q) error = %v, want error %v", tt.version, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			if config.Version != tt.version {
+				t.Errorf("Migrate(version %q) changed the version to %q", tt.version, config.Version)
+			}
+			continue
+		}
+		if migrated != tt.migrated {
+			t.Errorf("Migrate(version %q) = %v, want %v", tt.version, migrated, tt.migrated)
+		}
+		if config.Version != "2" {
+			t.Errorf("Migrate(version %q) set version %q", tt.version, config.Version)
+		}
+	}
+}
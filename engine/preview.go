@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kierank/pipes/nodes"
+	"github.com/kierank/pipes/store"
+)
+
+const (
+	defaultPreviewLimit = 20
+	maxPreviewLimit     = 100
+)
+
+// PreviewOptions control a preview run.
+type PreviewOptions struct {
+	// Config previews unsaved changes instead of the pipe's stored config.
+	// It should be at the current version.
+	Config *PipeConfig
+
+	// UseCache serves sources from the source cache wherever it has an
+	// entry, instead of fetching again.
+	UseCache bool
+
+	// Limit is how many items to return from each port of the previewed
+	// node; 0 uses the default.
+	Limit int
+
+	// Payload stands in for the trigger payload, for pipes that read one
+	Payload interface{}
+}
+
+// PreviewResult is what a node produced in a preview run, along with how
+// every node it depends on fared.
+type PreviewResult struct {
+	NodeID string `json:"node_id"`
+
+	// Items samples the node's output and Total counts all of it. Ports
+	// samples each output port of nodes that have several.
+	Items []interface{}            `json:"items"`
+	Total int                      `json:"total"`
+	Ports map[string][]interface{} `json:"ports,omitempty"`
+
+	// Nodes lists the nodes that ran, in the order they finished
+	Nodes      []NodePreview      `json:"nodes"`
+	Logs       []nodes.PreviewLog `json:"logs"`
+	DurationMs int64              `json:"duration_ms"`
+
+	// Error is set if the run failed, in which case only the nodes that
+	// finished have results
+	Error string `json:"error,omitempty"`
+}
+
+// NodePreview is how one node fared in a preview run. Ports counts the
+// items on each output port of nodes that have several.
+type NodePreview struct {
+	NodeID     string         `json:"node_id"`
+	Type       string         `json:"type"`
+	Items      int            `json:"items"`
+	Ports      map[string]int `json:"ports,omitempty"`
+	DurationMs int64          `json:"duration_ms"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// previewRun records what each node of a preview run did. Only
+// executePipeline's goroutine records nodes.
+type previewRun struct {
+	*nodes.Preview
+	nodes   []NodePreview
+	outputs map[string]*nodeOutput
+}
+
+func (p *previewRun) record(node *Node, output *nodeOutput, err error, duration time.Duration) {
+	result := NodePreview{NodeID: node.ID, Type: node.Type, DurationMs: duration.Milliseconds()}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		if p.outputs == nil {
+			p.outputs = make(map[string]*nodeOutput)
+		}
+		p.outputs[node.ID] = output
+
+		result.Items = output.count()
+		if output.defaultPort != "" {
+			result.Ports = make(map[string]int, len(output.names))
+			for _, name := range output.names {
+				result.Ports[name] = len(output.ports[name])
+			}
+		}
+	}
+	p.nodes = append(p.nodes, result)
+}
+
+// Preview runs the nodes a node depends on, and the node itself, without
+// side effects (see nodes.Preview), and returns a sample of its output.
+// Nothing is recorded: there is no execution, and no events are published.
+// Configs that aren't valid are rejected with ValidationErrors; a failed
+// run is reported in the result.
+func (e *Executor) Preview(ctx context.Context, pipe *store.Pipe, nodeID string, opts PreviewOptions) (*PreviewResult, error) {
+	config := opts.Config
+	if config == nil {
+		var err error
+		if config, err = ParseConfig(pipe.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	if findNode(config.Nodes, nodeID) == nil {
+		return nil, fmt.Errorf("node not found: %s", nodeID)
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultPreviewLimit
+	}
+	if limit > maxPreviewLimit {
+		limit = maxPreviewLimit
+	}
+
+	run := &previewRun{Preview: &nodes.Preview{UseCache: opts.UseCache}}
+	start := time.Now()
+	pipelineResult, err := e.preview(ctx, pipe, config.upstream(nodeID), ExecuteOptions{
+		TriggerType: "preview",
+		Payload:     opts.Payload,
+		preview:     run,
+	})
+
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		return nil, verrs
+	}
+
+	result := &PreviewResult{
+		NodeID:     nodeID,
+		Items:      []interface{}{},
+		Nodes:      run.nodes,
+		Logs:       run.Logs(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if result.Nodes == nil {
+		result.Nodes = []NodePreview{}
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	output := pipelineResult.outputs[nodeID]
+	items := output.port("")
+	result.Items = sample(items, limit)
+	result.Total = len(items)
+	if output.defaultPort != "" {
+		result.Ports = make(map[string][]interface{}, len(output.names))
+		for _, name := range output.names {
+			result.Ports[name] = sample(output.ports[name], limit)
+		}
+	}
+
+	return result, nil
+}
+
+// preview validates and runs a pipeline for a preview, within the pipe's
+// timeout.
+func (e *Executor) preview(ctx context.Context, pipe *store.Pipe, config *PipeConfig, opts ExecuteOptions) (*pipelineResult, error) {
+	if errs := e.registry.Validate(config); errs != nil {
+		return nil, errs
+	}
+
+	if config.Settings.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(config.Settings.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	result, err := e.executePipeline(ctx, "", pipe, config, &opts)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %ds: %w", config.Settings.Timeout, err)
+	}
+	return result, err
+}
+
+// upstream returns the part of the config that a node depends on: the node,
+// every node with a path to it, and the connections between them.
+func (c *PipeConfig) upstream(nodeID string) *PipeConfig {
+	keep := map[string]bool{nodeID: true}
+	queue := []string{nodeID}
+	for len(queue) > 0 {
+		target := queue[0]
+		queue = queue[1:]
+		for _, conn := range c.Connections {
+			if conn.Target == target && !keep[conn.Source] {
+				keep[conn.Source] = true
+				queue = append(queue, conn.Source)
+			}
+		}
+	}
+
+	sub := &PipeConfig{Version: c.Version, Settings: c.Settings}
+	for _, n := range c.Nodes {
+		if keep[n.ID] {
+			sub.Nodes = append(sub.Nodes, n)
+		}
+	}
+	for _, conn := range c.Connections {
+		if keep[conn.Source] && keep[conn.Target] {
+			sub.Connections = append(sub.Connections, conn)
+		}
+	}
+	return sub
+}
+
+// sample returns up to limit items from the start of items.
+func sample(items []interface{}, limit int) []interface{} {
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	if items == nil {
+		return []interface{}{}
+	}
+	return items
+}
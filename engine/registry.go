@@ -24,15 +24,27 @@ func NewRegistry() *Registry {
 	// Sources
 	r.Register(&sources.RSSSourceNode{})
 	r.Register(&sources.HTTPSourceNode{})
+	r.Register(&sources.HTMLScraperNode{})
+	r.Register(&sources.TriggerPayloadNode{})
+	r.Register(&sources.PipeInputNode{})
+	r.Register(&sources.PipeFeedNode{})
 
 	// Transforms
 	r.Register(&transforms.FilterNode{})
+	r.Register(&transforms.SwitchNode{})
 	r.Register(&transforms.SortNode{})
 	r.Register(&transforms.LimitNode{})
 	r.Register(&transforms.MergeNode{})
+	r.Register(&transforms.JoinNode{})
+	r.Register(&transforms.GroupByNode{})
 	r.Register(&transforms.MapNode{})
+	r.Register(&transforms.ComputeNode{})
 	r.Register(&transforms.RegexNode{})
 	r.Register(&transforms.TruncateNode{})
+	r.Register(&transforms.NewItemsNode{})
+	r.Register(&transforms.HTTPEnrichNode{})
+	r.Register(&transforms.FullArticleNode{})
+	r.Register(&transforms.SubPipeNode{})
 
 	// Outputs
 	r.Register(&outputs.JSONOutputNode{})
@@ -0,0 +1,391 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kierank/pipes/nodes"
+	"github.com/kierank/pipes/store"
+)
+
+const (
+	defaultReplayLimit = 10
+	maxReplayLimit     = 100
+)
+
+// ReplayOptions control a replay.
+type ReplayOptions struct {
+	// Config replays an edited config instead of the one the execution
+	// ran with. It should be at the current version.
+	Config *PipeConfig
+
+	// Limit is how many added and removed items to list for each port; 0
+	// uses the default.
+	Limit int
+}
+
+// ReplayResult compares the output of every node in a replay with what it
+// output in the original execution.
+type ReplayResult struct {
+	ExecutionID string `json:"execution_id"`
+
+	// Revision is the pipe revision that was replayed, or 0 if an edited
+	// config was given or the pipe has no revision from the time of the run
+	Revision int `json:"revision,omitempty"`
+
+	// Nodes lists the nodes of the replayed config in order, followed by
+	// any that only the original run had
+	Nodes      []NodeDiff         `json:"nodes"`
+	Logs       []nodes.PreviewLog `json:"logs"`
+	DurationMs int64              `json:"duration_ms"`
+
+	// Error is set if the replay failed, in which case the nodes that
+	// didn't finish have no output to compare
+	Error string `json:"error,omitempty"`
+}
+
+// How a node's output in a replay compares with the original run.
+const (
+	ReplayUnchanged = "unchanged"
+	ReplayChanged   = "changed"
+	ReplayFailed    = "failed"
+
+	// ReplayNew and ReplayMissing mean only the replay or only the
+	// original run had output for the node. ReplaySkipped means neither
+	// did, e.g. because an upstream node failed.
+	ReplayNew     = "new"
+	ReplayMissing = "missing"
+	ReplaySkipped = "skipped"
+)
+
+// NodeDiff compares a node's output in the original run and the replay.
+// Before and After count its items in each, and are nil if it had no
+// output. Ports is only set when both runs had output.
+type NodeDiff struct {
+	NodeID string `json:"node_id"`
+	Type   string `json:"type,omitempty"`
+	Status string `json:"status"`
+
+	// Replayed is set for sources whose recorded output was replayed
+	Replayed bool `json:"replayed,omitempty"`
+
+	Before *int       `json:"before"`
+	After  *int       `json:"after"`
+	Ports  []PortDiff `json:"ports,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// PortDiff compares the items on one output port, by their JSON. Added and
+// Removed count the items only the replay or only the original had, and
+// AddedItems and RemovedItems list the first of them. Reordered is set
+// when both had the same items in a different order.
+type PortDiff struct {
+	Port         string        `json:"port,omitempty"`
+	Before       int           `json:"before"`
+	After        int           `json:"after"`
+	Added        int           `json:"added"`
+	Removed      int           `json:"removed"`
+	AddedItems   []interface{} `json:"added_items"`
+	RemovedItems []interface{} `json:"removed_items"`
+	Reordered    bool          `json:"reordered,omitempty"`
+}
+
+func (d PortDiff) changed() bool {
+	return d.Added > 0 || d.Removed > 0 || d.Reordered
+}
+
+// Replay re-runs a pipe against the output its sources recorded in an
+// earlier execution, and compares every node's output with the original.
+// By default it replays the config the pipe had when the execution
+// started. Like a preview, the replay has no side effects and records
+// nothing; it is also offline, so nodes other than the replayed sources
+// can only use cached responses (see nodes.Preview). Configs that aren't
+// valid are rejected with ValidationErrors; a failed replay is reported
+// in the result.
+func (e *Executor) Replay(ctx context.Context, pipe *store.Pipe, executionID string, opts ReplayOptions) (*ReplayResult, error) {
+	execution, err := e.db.GetExecution(executionID)
+	if err != nil {
+		return nil, fmt.Errorf("get execution: %w", err)
+	}
+	if execution == nil || execution.PipeID != pipe.ID {
+		return nil, fmt.Errorf("execution not found: %s", executionID)
+	}
+	if execution.Status == "running" {
+		return nil, fmt.Errorf("execution %s is still running", executionID)
+	}
+
+	result := &ReplayResult{ExecutionID: executionID, Nodes: []NodeDiff{}}
+
+	config := opts.Config
+	if config == nil {
+		configJSON := pipe.Config
+		rev, err := e.db.GetPipeRevisionAt(pipe.ID, execution.StartedAt)
+		if err != nil {
+			return nil, err
+		}
+		if rev != nil {
+			configJSON = rev.Config
+			result.Revision = rev.Revision
+		}
+		if config, err = ParseConfig(configJSON); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultReplayLimit
+	}
+	if limit > maxReplayLimit {
+		limit = maxReplayLimit
+	}
+
+	logged, err := e.db.GetExecutionOutputs(executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &previewRun{Preview: &nodes.Preview{Offline: true}}
+
+	loggedIDs := make([]string, 0, len(logged))
+	for nodeID := range logged {
+		loggedIDs = append(loggedIDs, nodeID)
+	}
+	sort.Strings(loggedIDs)
+
+	original := make(map[string]*nodeOutput, len(logged))
+	for _, nodeID := range loggedIDs {
+		data := logged[nodeID]
+		if size, ok := store.TruncatedPayload(data); ok {
+			run.Log(nodeID, "warn", fmt.Sprintf("Recorded output was too large to keep (%d bytes)", size))
+			continue
+		}
+		output, err := loggedOutput(data)
+		if err != nil {
+			run.Log(nodeID, "warn", fmt.Sprintf("Recorded output can't be read: %v", err))
+			continue
+		}
+		original[nodeID] = output
+	}
+
+	// Sources output what they did originally, instead of fetching again
+	recorded := make(map[string]*nodeOutput)
+	for _, n := range config.Nodes {
+		output, ok := original[n.ID]
+		if !ok {
+			continue
+		}
+		nodeImpl, err := e.registry.Get(n.Type)
+		if err != nil || nodeImpl.Category() != "source" {
+			continue
+		}
+		if multi, ok := nodeImpl.(nodes.MultiOutputNode); ok && output.defaultPort != "" {
+			replayed := *output
+			replayed.names = multi.OutputPorts(n.Config)
+			replayed.defaultPort = replayed.names[0]
+			output = &replayed
+		}
+		recorded[n.ID] = output
+	}
+
+	start := time.Now()
+	_, err = e.preview(ctx, pipe, config, ExecuteOptions{
+		TriggerType: "replay",
+		preview:     run,
+		recorded:    recorded,
+	})
+
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		return nil, verrs
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Logs = run.Logs()
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	failed := make(map[string]string)
+	for _, n := range run.nodes {
+		if n.Error != "" {
+			failed[n.NodeID] = n.Error
+		}
+	}
+
+	inConfig := make(map[string]bool, len(config.Nodes))
+	for _, n := range config.Nodes {
+		inConfig[n.ID] = true
+		_, replayed := recorded[n.ID]
+		diff := diffOutputs(original[n.ID], run.outputs[n.ID], limit)
+		diff.NodeID, diff.Type, diff.Replayed = n.ID, n.Type, replayed
+		if msg, ok := failed[n.ID]; ok {
+			diff.Status, diff.Error = ReplayFailed, msg
+		}
+		result.Nodes = append(result.Nodes, diff)
+	}
+
+	var removed []string
+	for nodeID := range original {
+		if !inConfig[nodeID] {
+			removed = append(removed, nodeID)
+		}
+	}
+	sort.Strings(removed)
+	for _, nodeID := range removed {
+		diff := diffOutputs(original[nodeID], nil, limit)
+		diff.NodeID = nodeID
+		result.Nodes = append(result.Nodes, diff)
+	}
+
+	return result, nil
+}
+
+// loggedOutput reads a node output written by logOutput: a list of items,
+// or an object of them keyed by port name for multi-output nodes.
+func loggedOutput(data string) (*nodeOutput, error) {
+	var items []interface{}
+	if err := json.Unmarshal([]byte(data), &items); err == nil {
+		return &nodeOutput{ports: map[string][]interface{}{"": items}}, nil
+	}
+
+	var ports map[string][]interface{}
+	if err := json.Unmarshal([]byte(data), &ports); err != nil {
+		return nil, err
+	}
+
+	output := &nodeOutput{ports: ports}
+	for name := range ports {
+		output.names = append(output.names, name)
+	}
+	sort.Strings(output.names)
+	if len(output.names) > 0 {
+		output.defaultPort = output.names[0]
+	}
+	return output, nil
+}
+
+// diffOutputs compares a node's output in the original run with its output
+// in the replay; either may be nil.
+func diffOutputs(before, after *nodeOutput, limit int) NodeDiff {
+	var diff NodeDiff
+	if before != nil {
+		n := before.count()
+		diff.Before = &n
+	}
+	if after != nil {
+		n := after.count()
+		diff.After = &n
+	}
+
+	switch {
+	case before == nil && after == nil:
+		diff.Status = ReplaySkipped
+		return diff
+	case before == nil:
+		diff.Status = ReplayNew
+		return diff
+	case after == nil:
+		diff.Status = ReplayMissing
+		return diff
+	}
+
+	diff.Status = ReplayUnchanged
+	for _, port := range portNames(after, before) {
+		portDiff := diffItems(before.ports[port], normalizeItems(after.ports[port]), limit)
+		portDiff.Port = port
+		if portDiff.changed() {
+			diff.Status = ReplayChanged
+		}
+		diff.Ports = append(diff.Ports, portDiff)
+	}
+	return diff
+}
+
+// portNames lists the ports of both outputs, without duplicates.
+func portNames(outputs ...*nodeOutput) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, o := range outputs {
+		ports := o.names
+		if o.defaultPort == "" {
+			ports = []string{""}
+		}
+		for _, name := range ports {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// normalizeItems round-trips items through JSON, so they compare equal to
+// the same items read back from the execution logs.
+func normalizeItems(items []interface{}) []interface{} {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return items
+	}
+	var normalized []interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return items
+	}
+	return normalized
+}
+
+// diffItems compares two lists of items as multisets of their JSON.
+func diffItems(before, after []interface{}, limit int) PortDiff {
+	diff := PortDiff{
+		Before:       len(before),
+		After:        len(after),
+		AddedItems:   []interface{}{},
+		RemovedItems: []interface{}{},
+	}
+
+	key := func(item interface{}) string {
+		data, _ := json.Marshal(item)
+		return string(data)
+	}
+
+	unmatched := make(map[string]int, len(before))
+	beforeKeys := make([]string, len(before))
+	for i, item := range before {
+		beforeKeys[i] = key(item)
+		unmatched[beforeKeys[i]]++
+	}
+
+	sameOrder := len(before) == len(after)
+	for i, item := range after {
+		k := key(item)
+		if sameOrder && k != beforeKeys[i] {
+			sameOrder = false
+		}
+		if unmatched[k] > 0 {
+			unmatched[k]--
+			continue
+		}
+		diff.Added++
+		if len(diff.AddedItems) < limit {
+			diff.AddedItems = append(diff.AddedItems, item)
+		}
+	}
+
+	for i, item := range before {
+		if unmatched[beforeKeys[i]] == 0 {
+			continue
+		}
+		unmatched[beforeKeys[i]]--
+		diff.Removed++
+		if len(diff.RemovedItems) < limit {
+			diff.RemovedItems = append(diff.RemovedItems, item)
+		}
+	}
+
+	diff.Reordered = diff.Added == 0 && diff.Removed == 0 && !sameOrder
+	return diff
+}
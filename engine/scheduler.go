@@ -2,6 +2,8 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -9,22 +11,36 @@ import (
 )
 
 type Scheduler struct {
-	db       *store.DB
-	executor *Executor
-	ticker   *time.Ticker
-	done     chan struct{}
-	logger   *log.Logger
+	db      *store.DB
+	manager *Manager
+	ticker  *time.Ticker
+	done    chan struct{}
+	logger  *log.Logger
+
+	// Old executions are pruned at most once per pruneInterval
+	retention  store.PruneOptions
+	pruneMu    sync.Mutex
+	lastPruned time.Time
 }
 
-func NewScheduler(db *store.DB, logger *log.Logger) *Scheduler {
+// pruneInterval is how often the scheduler prunes old executions.
+const pruneInterval = time.Hour
+
+func NewScheduler(db *store.DB, manager *Manager, logger *log.Logger) *Scheduler {
 	return &Scheduler{
-		db:       db,
-		executor: NewExecutor(db),
-		done:     make(chan struct{}),
-		logger:   logger,
+		db:      db,
+		manager: manager,
+		done:    make(chan struct{}),
+		logger:  logger,
 	}
 }
 
+// SetRetention makes the scheduler prune executions the options select.
+// It must be called before Start.
+func (s *Scheduler) SetRetention(opts store.PruneOptions) {
+	s.retention = opts
+}
+
 func (s *Scheduler) Start() {
 	s.logger.Info("scheduler starting")
 
@@ -50,6 +66,8 @@ func (s *Scheduler) tick() {
 	ctx := context.Background()
 	now := time.Now().Unix()
 
+	s.prune()
+
 	jobs, err := s.db.GetDueJobs(now)
 	if err != nil {
 		s.logger.Error("error fetching jobs", "error", err)
@@ -67,20 +85,55 @@ func (s *Scheduler) tick() {
 	}
 }
 
+// prune deletes old executions if pruning is due. Ticks can overlap while
+// jobs run, so only one of them prunes.
+func (s *Scheduler) prune() {
+	if s.retention.KeepPerPipe <= 0 && s.retention.MaxAge <= 0 {
+		return
+	}
+	if !s.pruneMu.TryLock() {
+		return
+	}
+	defer s.pruneMu.Unlock()
+
+	if time.Since(s.lastPruned) < pruneInterval {
+		return
+	}
+	s.lastPruned = time.Now()
+
+	result, err := s.db.PruneExecutions(s.retention)
+	if err != nil {
+		s.logger.Error("error pruning executions", "error", err)
+		return
+	}
+	if result.Executions > 0 {
+		s.logger.Info("pruned old executions", "executions", result.Executions, "logs", result.Logs)
+	}
+}
+
 func (s *Scheduler) executeJob(ctx context.Context, job *store.ScheduledJob) error {
 	// Execute pipeline
-	_, err := s.executor.Execute(ctx, job.PipeID, "scheduled")
+	_, err := s.manager.Run(ctx, job.PipeID, ExecuteOptions{TriggerType: "scheduled"})
 	if err != nil {
 		s.logger.Error("pipeline execution failed", "pipe_id", job.PipeID, "error", err)
 	}
 
-	// Calculate next run time (simplified: add 1 hour for now)
-	// In production, use a proper cron parser
-	nextRun := time.Now().Add(1 * time.Hour).Unix()
+	// Calculate next run time from the cron expression
+	now := time.Now()
+	sched, err := ParseCron(job.CronExpression, job.Timezone)
+	if err != nil {
+		s.logger.Error("invalid cron expression, disabling job", "job_id", job.ID, "expression", job.CronExpression, "error", err)
+		return s.db.DisableScheduledJob(job.ID)
+	}
+
+	next := sched.Next(now)
+	if next.IsZero() {
+		s.logger.Warn("cron expression never fires again, disabling job", "job_id", job.ID, "expression", job.CronExpression)
+		return s.db.DisableScheduledJob(job.ID)
+	}
 
 	// Update job
-	now := time.Now().Unix()
-	return s.db.UpdateJobAfterRun(job.ID, now, nextRun)
+	return s.db.UpdateJobAfterRun(job.ID, now.Unix(), next.Unix())
 }
 
 func (s *Scheduler) Stop() {
@@ -91,3 +144,46 @@ func (s *Scheduler) Stop() {
 	close(s.done)
 	s.logger.Info("scheduler stopped")
 }
+
+// SyncSchedule brings a pipe's scheduled_jobs row in line with its settings:
+// no schedule deletes the job, a disabled schedule keeps the job but turns it
+// off, and an enabled schedule (re)computes the next run from now.
+func SyncSchedule(db *store.DB, pipeID string, settings *Settings) error {
+	job, err := PlanSchedule(pipeID, settings)
+	if err != nil {
+		return err
+	}
+
+	if job == nil {
+		return db.DeleteScheduledJobByPipe(pipeID)
+	}
+
+	return db.UpsertScheduledJob(pipeID, job.CronExpression, job.Timezone, job.NextRunAt, job.Enabled)
+}
+
+// PlanSchedule returns the scheduled job a pipe's settings call for, as
+// SyncSchedule would save it, or nil if the pipe has no schedule. Use it
+// with store.UpdatePipeSchedule to save a pipe and its job together.
+func PlanSchedule(pipeID string, settings *Settings) (*store.ScheduledJob, error) {
+	sched, err := settings.ParseSchedule()
+	if err != nil {
+		return nil, fmt.Errorf("parse schedule: %w", err)
+	}
+
+	if sched == nil {
+		return nil, nil
+	}
+
+	next := sched.Next(time.Now())
+	if next.IsZero() {
+		return nil, fmt.Errorf("schedule %q never fires", settings.Schedule)
+	}
+
+	return &store.ScheduledJob{
+		PipeID:         pipeID,
+		CronExpression: settings.Schedule,
+		Timezone:       settings.Timezone,
+		NextRunAt:      next.Unix(),
+		Enabled:        settings.Enabled,
+	}, nil
+}
@@ -0,0 +1,262 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+// ValidationError is one problem found in a pipe config. NodeID or
+// ConnectionID says what it belongs to; both are empty for problems with
+// the pipe as a whole.
+type ValidationError struct {
+	NodeID       string `json:"node_id,omitempty"`
+	ConnectionID string `json:"connection_id,omitempty"`
+	Field        string `json:"field,omitempty"`
+	Message      string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	switch {
+	case e.NodeID != "":
+		return fmt.Sprintf("node %s: %s", e.NodeID, e.Message)
+	case e.ConnectionID != "":
+		return fmt.Sprintf("connection %s: %s", e.ConnectionID, e.Message)
+	}
+	return e.Message
+}
+
+// ValidationErrors lists every problem found in a pipe config.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no errors"
+	case 1:
+		return "invalid pipe: " + e[0].Error()
+	}
+	return fmt.Sprintf("invalid pipe: %s (and %d more)", e[0].Error(), len(e)-1)
+}
+
+// Validate checks a pipe config before it is run or scheduled: every node
+// must have a known type and a valid config, connections must join
+// existing nodes and ports without forming cycles, and every node that
+// takes input must be connected to something upstream. It returns nil if
+// the config is valid.
+func (r *Registry) Validate(config *PipeConfig) ValidationErrors {
+	var errs ValidationErrors
+	nodeErr := func(nodeID, field, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{NodeID: nodeID, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	impls := make(map[string]nodes.Node, len(config.Nodes))
+	for i := range config.Nodes {
+		node := &config.Nodes[i]
+		if node.ID == "" {
+			errs = append(errs, ValidationError{Message: fmt.Sprintf("node %d has no ID", i+1)})
+			continue
+		}
+		if _, dup := impls[node.ID]; dup {
+			nodeErr(node.ID, "", "duplicate node ID")
+			continue
+		}
+
+		impl, err := r.Get(node.Type)
+		if err != nil {
+			impls[node.ID] = nil
+			nodeErr(node.ID, "", "unknown node type %q", node.Type)
+			continue
+		}
+		impls[node.ID] = impl
+
+		// Only run the node's own checks once its required fields are
+		// there, as they tend to repeat them
+		missing := false
+		for _, field := range impl.GetConfigSchema().Fields {
+			if field.Required && field.DefaultValue == nil && isBlank(node.Config[field.Name]) {
+				nodeErr(node.ID, field.Name, "%s is required", field.Label)
+				missing = true
+			}
+		}
+		if !missing {
+			if err := impl.ValidateConfig(node.Config); err != nil {
+				nodeErr(node.ID, "", "%s", err.Error())
+			}
+		}
+	}
+
+	incoming := make(map[string][]Connection)
+	outgoing := make(map[string][]Connection)
+	for _, conn := range config.Connections {
+		connErr := func(format string, args ...interface{}) {
+			errs = append(errs, ValidationError{ConnectionID: conn.ID, Message: fmt.Sprintf(format, args...)})
+		}
+
+		source, sourceOK := impls[conn.Source]
+		target, targetOK := impls[conn.Target]
+		if !sourceOK {
+			connErr("source node %q doesn't exist", conn.Source)
+		}
+		if !targetOK {
+			connErr("target node %q doesn't exist", conn.Target)
+		}
+		if !sourceOK || !targetOK {
+			continue
+		}
+		if conn.Source == conn.Target {
+			connErr("node %s is connected to itself", conn.Source)
+			continue
+		}
+
+		incoming[conn.Target] = append(incoming[conn.Target], conn)
+		outgoing[conn.Source] = append(outgoing[conn.Source], conn)
+
+		// Ports can only be checked on known node types
+		if source != nil && conn.SourceHandle != "" {
+			sourceNode := findNode(config.Nodes, conn.Source)
+			if ports := nodes.OutputPorts(source, sourceNode.Config); len(ports) > 0 && !slices.Contains(ports, conn.SourceHandle) {
+				connErr("node %s has no output %q", conn.Source, conn.SourceHandle)
+			}
+		}
+		if target != nil {
+			if ports := nodes.InputPorts(target); len(ports) > 0 && portIndex(ports, conn.TargetHandle) < 0 {
+				connErr("node %s has no input %q", conn.Target, conn.TargetHandle)
+			}
+		}
+	}
+
+	checked := make(map[string]bool, len(config.Nodes))
+	for _, node := range config.Nodes {
+		impl := impls[node.ID]
+		if impl == nil || checked[node.ID] {
+			continue
+		}
+		checked[node.ID] = true
+
+		in := len(incoming[node.ID])
+		switch {
+		case impl.Inputs() == 0 && in > 0:
+			nodeErr(node.ID, "", "%s takes no input, but something is connected to it", impl.Label())
+		case impl.Inputs() > 0 && in == 0:
+			nodeErr(node.ID, "", "%s has no input connected", impl.Label())
+		case impl.Inputs() == 1 && in > 1:
+			nodeErr(node.ID, "", "%s takes 1 input, but %d are connected; use a Merge node to combine them", impl.Label(), in)
+		}
+
+		if impl.Outputs() == 0 && len(outgoing[node.ID]) > 0 {
+			nodeErr(node.ID, "", "%s has no output, but it is connected to other nodes", impl.Label())
+		}
+
+		if ports := nodes.InputPorts(impl); len(ports) > 0 && in > 0 {
+			for i, port := range ports {
+				connected := false
+				for _, conn := range incoming[node.ID] {
+					if portIndex(ports, conn.TargetHandle) == i {
+						connected = true
+						break
+					}
+				}
+				if !connected {
+					nodeErr(node.ID, "", "%s input %q is not connected", impl.Label(), port)
+				}
+			}
+		}
+	}
+
+	cyclic := cycleNodes(config.Nodes, incoming, outgoing)
+	for _, node := range config.Nodes {
+		if cyclic[node.ID] {
+			nodeErr(node.ID, "", "part of a cycle")
+		}
+	}
+
+	// Outputs must be fed, however indirectly, by a source
+	fed := make(map[string]bool)
+	var feed func(id string)
+	feed = func(id string) {
+		if fed[id] {
+			return
+		}
+		fed[id] = true
+		for _, conn := range outgoing[id] {
+			feed(conn.Target)
+		}
+	}
+	for _, node := range config.Nodes {
+		if impl := impls[node.ID]; impl != nil && impl.Inputs() == 0 {
+			feed(node.ID)
+		}
+	}
+	for _, node := range config.Nodes {
+		impl := impls[node.ID]
+		if impl != nil && impl.Category() == "output" && len(incoming[node.ID]) > 0 && !fed[node.ID] {
+			nodeErr(node.ID, "", "%s has no source upstream", impl.Label())
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// cycleNodes returns the nodes left over by Kahn's algorithm, which are
+// the ones on or downstream of a cycle, trimmed to those that can also
+// reach themselves.
+func cycleNodes(nodeList []Node, incoming, outgoing map[string][]Connection) map[string]bool {
+	inDegree := make(map[string]int, len(nodeList))
+	var queue []string
+	for _, n := range nodeList {
+		inDegree[n.ID] = len(incoming[n.ID])
+		if inDegree[n.ID] == 0 {
+			queue = append(queue, n.ID)
+		}
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, conn := range outgoing[id] {
+			inDegree[conn.Target]--
+			if inDegree[conn.Target] == 0 {
+				queue = append(queue, conn.Target)
+			}
+		}
+	}
+
+	cyclic := make(map[string]bool)
+	for id, degree := range inDegree {
+		if degree > 0 && reaches(id, id, outgoing, make(map[string]bool)) {
+			cyclic[id] = true
+		}
+	}
+	return cyclic
+}
+
+func reaches(from, to string, outgoing map[string][]Connection, visited map[string]bool) bool {
+	for _, conn := range outgoing[from] {
+		if conn.Target == to {
+			return true
+		}
+		if !visited[conn.Target] {
+			visited[conn.Target] = true
+			if reaches(conn.Target, to, outgoing, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isBlank reports whether a config value is missing or an empty string,
+// which is how the editor stores fields left empty.
+func isBlank(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	s, ok := v.(string)
+	return ok && strings.TrimSpace(s) == ""
+}
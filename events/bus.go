@@ -0,0 +1,143 @@
+// Package events is an in-process pub/sub of execution progress, used to
+// stream logs and status to clients while a pipe runs.
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	TypeLog        = "log"
+	TypeNodeStart  = "node_start"
+	TypeNodeFinish = "node_finish"
+	TypeStatus     = "status"
+)
+
+// retention is how long a finished execution's events stay available to
+// late subscribers.
+const retention = time.Minute
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// it is dropped, so a slow client never blocks an execution.
+const subscriberBuffer = 256
+
+type Event struct {
+	Type        string          `json:"type"`
+	ExecutionID string          `json:"execution_id"`
+	NodeID      string          `json:"node_id,omitempty"`
+	Level       string          `json:"level,omitempty"`
+	Message     string          `json:"message,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	Status      string          `json:"status,omitempty"`
+	Items       *int            `json:"items,omitempty"`
+	DurationMs  *int64          `json:"duration_ms,omitempty"`
+	Timestamp   int64           `json:"timestamp"`
+}
+
+// Terminal reports whether the event is an execution's final status.
+func (e *Event) Terminal() bool {
+	return e.Type == TypeStatus && e.Status != "" && e.Status != "running"
+}
+
+type stream struct {
+	history []Event
+	subs    map[chan Event]struct{}
+	done    bool
+}
+
+type Bus struct {
+	mu      sync.Mutex
+	streams map[string]*stream
+}
+
+func NewBus() *Bus {
+	return &Bus{streams: make(map[string]*stream)}
+}
+
+// Publish records an event in its execution's history and sends it to
+// subscribers. A terminal status event closes the stream.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.Timestamp == 0 {
+		e.Timestamp = time.Now().Unix()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.streams[e.ExecutionID]
+	if !ok {
+		s = &stream{subs: make(map[chan Event]struct{})}
+		b.streams[e.ExecutionID] = s
+	}
+	if s.done {
+		return
+	}
+
+	s.history = append(s.history, e)
+
+	for ch := range s.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+
+	if e.Terminal() {
+		s.done = true
+		for ch := range s.subs {
+			close(ch)
+		}
+		s.subs = nil
+
+		id := e.ExecutionID
+		time.AfterFunc(retention, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.streams[id] == s {
+				delete(b.streams, id)
+			}
+		})
+	}
+}
+
+// Subscribe returns the events published so far for an execution and a
+// channel of the ones that follow. The channel is closed after the final
+// status event, or if the subscriber falls too far behind. ok is false if
+// the bus has no events for the execution.
+func (b *Bus) Subscribe(executionID string) (history []Event, ch <-chan Event, unsubscribe func(), ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.streams[executionID]
+	if !ok {
+		return nil, nil, func() {}, false
+	}
+
+	history = append([]Event(nil), s.history...)
+
+	c := make(chan Event, subscriberBuffer)
+	if s.done {
+		close(c)
+		return history, c, func() {}, true
+	}
+
+	s.subs[c] = struct{}{}
+	unsubscribe = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := s.subs[c]; ok {
+			delete(s.subs, c)
+			close(c)
+		}
+	}
+
+	return history, c, unsubscribe, true
+}
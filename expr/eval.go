@@ -0,0 +1,500 @@
+// Package expr is a small, sandboxed expression language for computing
+// values from items. Expressions can read the item's fields, do arithmetic,
+// compare, combine conditions and call a fixed set of string, math and date
+// functions, but have no loops and no access to anything outside the item.
+//
+//	title ?? "untitled"
+//	lower(author.name) == "kieran" && published_at > now() - days(1)
+//	categories[0] ?? "none"
+//	$["content:encoded"]
+//
+// Missing fields are null. Member access and indexing on null give null,
+// arithmetic with null gives null, and comparisons with null are false, so
+// expressions degrade gracefully on items without a field; use ?? to
+// supply a default.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Program is a compiled expression. It is safe for concurrent use.
+type Program struct {
+	src  string
+	root node
+}
+
+// Compile parses an expression. Errors are *SyntaxError.
+func Compile(src string) (*Program, error) {
+	root, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{src: src, root: root}, nil
+}
+
+// String returns the expression's source.
+func (p *Program) String() string {
+	return p.src
+}
+
+// Eval evaluates the expression against an item. Bare field names refer to
+// the item's fields and $ to the whole item.
+func (p *Program) Eval(item interface{}) (interface{}, error) {
+	return p.root.eval(item)
+}
+
+// EvalBool evaluates the expression and reports whether the result is
+// truthy.
+func (p *Program) EvalBool(item interface{}) (bool, error) {
+	v, err := p.Eval(item)
+	if err != nil {
+		return false, err
+	}
+	return Truthy(v), nil
+}
+
+// Truthy reports whether a value counts as true in a condition: null,
+// false, 0, "" and empty arrays and objects are false.
+func Truthy(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	case []interface{}:
+		return len(val) > 0
+	case map[string]interface{}:
+		return len(val) > 0
+	}
+	if n, ok := toNumber(v); ok {
+		return n != 0
+	}
+	return true
+}
+
+type node interface {
+	eval(item interface{}) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (n *literal) eval(item interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+type field struct {
+	name string
+}
+
+func (n *field) eval(item interface{}) (interface{}, error) {
+	if n.name == "$" {
+		return normalize(item), nil
+	}
+	if m, ok := item.(map[string]interface{}); ok {
+		return normalize(m[n.name]), nil
+	}
+	return nil, nil
+}
+
+type index struct {
+	x   node
+	key node
+}
+
+func (n *index) eval(item interface{}) (interface{}, error) {
+	x, err := n.x.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	key, err := n.key.eval(item)
+	if err != nil {
+		return nil, err
+	}
+
+	switch val := x.(type) {
+	case map[string]interface{}:
+		if k, ok := key.(string); ok {
+			return normalize(val[k]), nil
+		}
+	case []interface{}:
+		if i, ok := position(key, len(val)); ok {
+			return normalize(val[i]), nil
+		}
+	case string:
+		runes := []rune(val)
+		if i, ok := position(key, len(runes)); ok {
+			return string(runes[i]), nil
+		}
+	}
+	return nil, nil
+}
+
+// position converts an index to a position in a sequence of length n,
+// counting negative indexes from the end.
+func position(key interface{}, n int) (int, bool) {
+	f, ok := key.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, false
+	}
+	i := int(f)
+	if i < 0 {
+		i += n
+	}
+	return i, i >= 0 && i < n
+}
+
+type call struct {
+	name string
+	fn   *function
+	args []node
+}
+
+func (n *call) eval(item interface{}) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	v, err := n.fn.call(args)
+	if err == nil {
+		err = checkLen(v)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", n.name, err)
+	}
+	return v, nil
+}
+
+// maxLen bounds the strings (in bytes) and arrays an expression can build,
+// so that functions like replace can't be nested to exhaust memory.
+const maxLen = 1 << 20
+
+func checkLen(v interface{}) error {
+	switch val := v.(type) {
+	case string:
+		if len(val) > maxLen {
+			return errTooLong
+		}
+	case []interface{}:
+		if len(val) > maxLen {
+			return fmt.Errorf("result has more than %d elements", maxLen)
+		}
+	}
+	return nil
+}
+
+var errTooLong = fmt.Errorf("result longer than %d bytes", maxLen)
+
+type unary struct {
+	op string
+	x  node
+}
+
+func (n *unary) eval(item interface{}) (interface{}, error) {
+	x, err := n.x.eval(item)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return !Truthy(x), nil
+	}
+
+	// Negation
+	if x == nil {
+		return nil, nil
+	}
+	f, ok := x.(float64)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", typeName(x))
+	}
+	return -f, nil
+}
+
+type logical struct {
+	op          string
+	left, right node
+}
+
+func (n *logical) eval(item interface{}) (interface{}, error) {
+	left, err := n.left.eval(item)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "??":
+		if left != nil {
+			return left, nil
+		}
+		return n.right.eval(item)
+	case "&&":
+		if !Truthy(left) {
+			return false, nil
+		}
+	case "||":
+		if Truthy(left) {
+			return true, nil
+		}
+	}
+
+	right, err := n.right.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	return Truthy(right), nil
+}
+
+type conditional struct {
+	cond, then, otherwise node
+}
+
+func (n *conditional) eval(item interface{}) (interface{}, error) {
+	cond, err := n.cond.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	if Truthy(cond) {
+		return n.then.eval(item)
+	}
+	return n.otherwise.eval(item)
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+func (n *binary) eval(item interface{}) (interface{}, error) {
+	left, err := n.left.eval(item)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(item)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "+":
+		return add(left, right)
+	}
+
+	// Remaining arithmetic operators only work on numbers
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	a, aok := left.(float64)
+	b, bok := right.(float64)
+	if !aok || !bok {
+		return nil, fmt.Errorf("cannot apply %s to %s and %s", n.op, typeName(left), typeName(right))
+	}
+
+	switch n.op {
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(a, b), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+// add adds numbers, concatenates arrays, and otherwise concatenates as
+// strings if either side is a string.
+func add(left, right interface{}) (interface{}, error) {
+	if a, ok := left.(float64); ok {
+		if b, ok := right.(float64); ok {
+			return a + b, nil
+		}
+	}
+
+	_, ls := left.(string)
+	_, rs := right.(string)
+	if ls || rs {
+		sum := toString(left) + toString(right)
+		return sum, checkLen(sum)
+	}
+
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	if a, ok := left.([]interface{}); ok {
+		if b, ok := right.([]interface{}); ok {
+			sum := append(append([]interface{}{}, a...), b...)
+			return sum, checkLen(sum)
+		}
+	}
+
+	return nil, fmt.Errorf("cannot add %s and %s", typeName(left), typeName(right))
+}
+
+// compare orders two numbers or two strings. Comparisons involving null
+// are false.
+func compare(op string, left, right interface{}) (interface{}, error) {
+	if left == nil || right == nil {
+		return false, nil
+	}
+
+	var c int
+	switch a := left.(type) {
+	case float64:
+		b, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %s and %s", typeName(left), typeName(right))
+		}
+		switch {
+		case a < b:
+			c = -1
+		case a > b:
+			c = 1
+		}
+	case string:
+		b, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %s and %s", typeName(left), typeName(right))
+		}
+		c = strings.Compare(a, b)
+	default:
+		return nil, fmt.Errorf("cannot compare %s and %s", typeName(left), typeName(right))
+	}
+
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// normalize converts the Go types found in items (e.g. int64 timestamps
+// and []string categories from sources) to the JSON-like types expressions
+// work with: float64, []interface{} and map[string]interface{}.
+func normalize(v interface{}) interface{} {
+	switch v.(type) {
+	case nil, float64, string, bool, []interface{}, map[string]interface{}:
+		return v
+	}
+	if n, ok := toNumber(v); ok {
+		return n
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return items
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String || rv.IsNil() {
+			return v
+		}
+		m := make(map[string]interface{}, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		return m
+	}
+	return v
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case float32:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	case uint:
+		return float64(val), true
+	case uint64:
+		return float64(val), true
+	case uint32:
+		return float64(val), true
+	}
+	return 0, false
+}
+
+// toString formats a value for string operations. Null is the empty
+// string and whole numbers have no decimal point.
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
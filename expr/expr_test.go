@@ -0,0 +1,328 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var testItem = map[string]interface{}{
+	"title":        "Go 1.24 Released",
+	"score":        float64(42),
+	"count":        int64(7),
+	"zero":         float64(0),
+	"flag":         true,
+	"empty":        "",
+	"categories":   []string{"golang", "release"},
+	"items":        []interface{}{float64(1), float64(2), float64(3)},
+	"author":       map[string]interface{}{"name": "Ann", "email": "ann@example.com"},
+	"content:body": "<p>hi</p>",
+	"published":    "2026-01-15T10:00:00Z",
+	"timestamp":    float64(1768471200),
+}
+
+func eval(t *testing.T, src string) (interface{}, error) {
+	t.Helper()
+	prog, err := Compile(src)
+	if err != nil {
+		t.Fatalf("Compile(%q): %v", src, err)
+	}
+	return prog.Eval(testItem)
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		// Precedence and associativity
+		{src: "1 + 2 * 3", want: 7.0},
+		{src: "(1 + 2) * 3", want: 9.0},
+		{src: "10 - 4 - 3", want: 3.0},
+		{src: "2 * 3 % 4", want: 2.0},
+		{src: "-2 * 3", want: -6.0},
+		{src: "--2", want: 2.0},
+		{src: "!false && false", want: false},
+		{src: "1 + 2 < 4 == true", want: true},
+		{src: "true || false && false", want: true},
+		{src: "(true || false) && false", want: false},
+		{src: "false or true and true", want: true},
+		{src: "not flag or true", want: true},
+		{src: "missing ?? 1 + 1", want: 2.0},
+		{src: "missing ?? other ?? 3", want: 3.0},
+		{src: "score > 40 ? \"high\" : \"low\"", want: "high"},
+		{src: "score > 50 ? 1 : score > 40 ? 2 : 3", want: 2.0},
+		{src: "missing ?? false ? 1 : 2", want: 2.0},
+
+		// Fields
+		{src: "title", want: "Go 1.24 Released"},
+		{src: "author.name", want: "Ann"},
+		{src: "author[\"email\"]", want: "ann@example.com"},
+		{src: "$[\"content:body\"]", want: "<p>hi</p>"},
+		{src: "categories[0]", want: "golang"},
+		{src: "categories[-1]", want: "release"},
+		{src: "categories[5]", want: nil},
+		{src: "categories[0.5]", want: nil},
+		{src: "title[0]", want: "G"},
+		{src: "missing.deeper[0]", want: nil},
+
+		// Types are normalized and coerced only where documented
+		{src: "count + 1", want: 8.0},
+		{src: "count == 7", want: true},
+		{src: "\"a\" + 1", want: "a1"},
+		{src: "1 + \"a\"", want: "1a"},
+		{src: "\"x\" + missing", want: "x"},
+		{src: "1.5 + \"\"", want: "1.5"},
+		{src: "split(\"a,b\", \",\") + items", want: []interface{}{"a", "b", 1.0, 2.0, 3.0}},
+		{src: "missing + 1", want: nil},
+		{src: "missing * 2", want: nil},
+		{src: "-missing", want: nil},
+		{src: "missing < 1", want: false},
+		{src: "missing == null", want: true},
+		{src: "zero == null", want: false},
+		{src: "\"1\" == 1", want: false},
+		{src: "\"abc\" < \"abd\"", want: true},
+		{src: "categories == categories", want: true},
+		{src: "!empty", want: true},
+		{src: "!items", want: false},
+		{src: "score && title", want: true},
+		{src: "zero || empty", want: false},
+
+		// Functions
+		{src: "lower(title)", want: "go 1.24 released"},
+		{src: "upper(missing)", want: nil},
+		{src: "trim(\"  x  \")", want: "x"},
+		{src: "startsWith(title, \"Go\")", want: true},
+		{src: "endsWith(title, \"go\")", want: false},
+		{src: "replace(title, \" \", \"-\")", want: "Go-1.24-Released"},
+		{src: "substr(title, 3, 4)", want: "1.24"},
+		{src: "substr(title, -8)", want: "Released"},
+		{src: "substr(title, 100)", want: ""},
+		{src: "substr(title, 3, -1)", want: ""},
+		{src: "substr(title, 3, 1e300)", want: "1.24 Released"},
+		{src: "split(\"a,b\", \",\")", want: []interface{}{"a", "b"}},
+		{src: "join(categories, \"/\")", want: "golang/release"},
+		{src: "matches(title, \"^Go \\\\d\")", want: true},
+		{src: "string(score)", want: "42"},
+		{src: "len(title)", want: 16.0},
+		{src: "len(categories)", want: 2.0},
+		{src: "len(missing)", want: 0.0},
+		{src: "contains(categories, \"golang\")", want: true},
+		{src: "contains(items, 2)", want: true},
+		{src: "contains(author, \"name\")", want: true},
+		{src: "contains(title, \"1.24\")", want: true},
+		{src: "number(\" 12.5 \")", want: 12.5},
+		{src: "number(\"twelve\")", want: nil},
+		{src: "number(flag)", want: 1.0},
+		{src: "round(2.456, 2)", want: 2.46},
+		{src: "floor(-1.5)", want: -2.0},
+		{src: "ceil(1.2)", want: 2.0},
+		{src: "abs(-3)", want: 3.0},
+		{src: "min(3, missing, 1)", want: 1.0},
+		{src: "max(items)", want: 3.0},
+		{src: "min(missing)", want: nil},
+		{src: "date(published)", want: 1768471200.0},
+		{src: "date(\"soon\")", want: nil},
+		{src: "date(published) == timestamp", want: true},
+		{src: "formatDate(timestamp, \"2006-01-02\")", want: "2026-01-15"},
+		{src: "hours(2)", want: 7200.0},
+		{src: "days(1)", want: 86400.0},
+		{src: "coalesce(missing, empty, 1)", want: ""},
+	}
+
+	for _, tt := range tests {
+		got, err := eval(t, tt.src)
+		if err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.src, got, tt.want)
+		}
+	}
+}
+
+// nest applies format to src the given number of times.
+func nest(format, src string, times int) string {
+	for i := 0; i < times; i++ {
+		src = fmt.Sprintf(format, src)
+	}
+	return src
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{src: "score / zero", wantErr: "division by zero"},
+		{src: "score % zero", wantErr: "division by zero"},
+		{src: "title * 2", wantErr: "cannot apply * to string and number"},
+		{src: "flag - 1", wantErr: "cannot apply - to boolean and number"},
+		{src: "-title", wantErr: "cannot negate string"},
+		{src: "score < title", wantErr: "cannot compare number and string"},
+		{src: "flag > true", wantErr: "cannot compare boolean and boolean"},
+		{src: "flag + 1", wantErr: "cannot add boolean and number"},
+		{src: "author + author", wantErr: "cannot add object and object"},
+		{src: "round(title)", wantErr: "round(): expected a number, got string"},
+		{src: "join(title, \",\")", wantErr: "join(): expected an array, got string"},
+		{src: "len(score)", wantErr: "len(): expected a string, array or object, got number"},
+		{src: "matches(title, \"(\")", wantErr: "matches(): invalid pattern"},
+		{src: "max(1, title)", wantErr: "max(): expected a number, got string"},
+		{src: "lower(score / zero)", wantErr: "division by zero"},
+
+		// Strings and arrays can't grow without bound
+		{src: nest("replace(%s, \"\", title)", "title", 6), wantErr: "replace(): result longer than"},
+		{src: "join(split(" + nest("replace(%s, \"\", title)", "title", 2) + ", \"\"), " + nest("replace(%s, \"\", title)", "title", 2) + ")", wantErr: "join(): result longer than"},
+	}
+
+	for _, tt := range tests {
+		_, err := eval(t, tt.src)
+		if err == nil {
+			t.Errorf("%s succeeded, want error %q", tt.src, tt.wantErr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s error = %q, want %q", tt.src, err, tt.wantErr)
+		}
+	}
+}
+
+// Operators that have a result without their right side don't evaluate it.
+func TestShortCircuit(t *testing.T) {
+	for _, src := range []string{
+		"false && score / zero",
+		"true || score / zero",
+		"title ?? score / zero",
+		"true ? 1 : score / zero",
+		"false ? score / zero : 1",
+	} {
+		if _, err := eval(t, src); err != nil {
+			t.Errorf("%s: %v", src, err)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+		pos     int
+	}{
+		{src: "", wantErr: "empty expression", pos: 0},
+		{src: "   ", wantErr: "empty expression", pos: 0},
+		{src: "1 +", wantErr: "unexpected end of expression", pos: 3},
+		{src: "1 2", wantErr: "unexpected \"2\"", pos: 2},
+		{src: "(1 + 2", wantErr: "expected \")\"", pos: 6},
+		{src: "a[1", wantErr: "expected \"]\"", pos: 3},
+		{src: "a ? 1", wantErr: "expected \":\"", pos: 5},
+		{src: "a.1", wantErr: "expected field name after \".\"", pos: 2},
+		{src: "\"open", wantErr: "unterminated string", pos: 0},
+		{src: "\"bad \\x escape\"", wantErr: "unknown escape \\x", pos: 5},
+		{src: "1.2.3", wantErr: "invalid number", pos: 0},
+		{src: "a # b", wantErr: "unexpected character '#'", pos: 2},
+		{src: "a = 1", wantErr: "unexpected character '='", pos: 2},
+		{src: "nope(1)", wantErr: "unknown function \"nope\"", pos: 0},
+		{src: "lower()", wantErr: "lower() takes 1 argument, got 0", pos: 0},
+		{src: "substr(a)", wantErr: "substr() takes 2 to 3 arguments, got 1", pos: 0},
+		{src: "replace(a, b)", wantErr: "replace() takes 3 arguments, got 2", pos: 0},
+		{src: "min()", wantErr: "min() takes at least 1 argument", pos: 0},
+		{src: "and a", wantErr: "unexpected \"and\"", pos: 0},
+		{src: "a and", wantErr: "unexpected end of expression", pos: 5},
+		{src: "1e2 + .5", wantErr: "unexpected \".\"", pos: 6},
+		{src: strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100), wantErr: "nested too deeply"},
+		{src: strings.Repeat("-", 100) + "1", wantErr: "nested too deeply"},
+		{src: strings.Repeat("a+", 2100) + "a", wantErr: "longer than 4096 characters", pos: 4096},
+	}
+
+	for _, tt := range tests {
+		_, err := Compile(tt.src)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Compile(%q) error = %v, want a *SyntaxError", tt.src, err)
+			continue
+		}
+		if !strings.Contains(syntaxErr.Msg, tt.wantErr) {
+			t.Errorf("Compile(%q) error = %q, want %q", tt.src, syntaxErr.Msg, tt.wantErr)
+		}
+		if tt.pos != 0 && syntaxErr.Pos != tt.pos {
+			t.Errorf("Compile(%q) error at %d, want %d", tt.src, syntaxErr.Pos, tt.pos)
+		}
+	}
+}
+
+func TestTruthy(t *testing.T) {
+	for _, v := range []interface{}{nil, false, 0.0, int64(0), "", []interface{}{}, map[string]interface{}{}} {
+		if Truthy(v) {
+			t.Errorf("Truthy(%#v) = true", v)
+		}
+	}
+	for _, v := range []interface{}{true, 1.0, -1, "0", []interface{}{nil}, map[string]interface{}{"a": nil}, []string{}} {
+		if !Truthy(v) {
+			t.Errorf("Truthy(%#v) = false", v)
+		}
+	}
+}
+
+// Expressions come from users and run on every item, so no input may make
+// the parser or evaluator panic or hang.
+func FuzzCompile(f *testing.F) {
+	for _, seed := range []string{
+		"title ?? \"untitled\"",
+		"lower(author.name) == \"kieran\" && published_at > now() - days(1)",
+		"categories[0] ?? \"none\"",
+		"$[\"content:encoded\"]",
+		"score > 50 ? 1 : score > 40 ? 2 : 3",
+		"substr(title, -3, 2) + string(round(score / 3, 2))",
+		"contains(categories, \"go\") or not matches(title, \"^\\\\d+$\")",
+		"min(items) + max(1, 2, missing) % 7",
+		"join(split(title, \" \"), \"-\")",
+		"formatDate(date(published), \"2006-01-02\")",
+		"\"esc\\\"aped\\n\" + 'single'",
+		"1e308 * 10 - -1.5e-3",
+		"((((1))))",
+		"a.b.c[1][-1][\"d\"]",
+		"!!!flag",
+		"",
+		"(",
+		"\"",
+		"\\",
+		"1..2",
+		"a ? b",
+		"f(",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, src string) {
+		prog, err := Compile(src)
+		if err != nil {
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Compile(%q) error %v is not a *SyntaxError", src, err)
+			}
+			if syntaxErr.Pos < 0 || syntaxErr.Pos > len(src) {
+				t.Fatalf("Compile(%q) error position %d is outside the source", src, syntaxErr.Pos)
+			}
+			return
+		}
+
+		if prog.String() != src {
+			t.Fatalf("String() = %q, want %q", prog.String(), src)
+		}
+
+		for _, item := range []interface{}{testItem, nil, "text", map[string]interface{}{}} {
+			v, err := prog.Eval(item)
+			if err != nil {
+				continue
+			}
+			// Indexing works on runes, so valid input gives valid output
+			if s, ok := v.(string); ok && !utf8.ValidString(s) && utf8.ValidString(src) {
+				t.Fatalf("Eval(%q) = invalid UTF-8 %q", src, s)
+			}
+		}
+	})
+}
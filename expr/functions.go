@@ -0,0 +1,359 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type function struct {
+	minArgs int
+	maxArgs int // -1 for any number
+	call    func(args []interface{}) (interface{}, error)
+}
+
+func (f *function) arity() string {
+	switch {
+	case f.minArgs == f.maxArgs && f.minArgs == 1:
+		return "1 argument"
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d arguments", f.minArgs)
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.minArgs)
+	default:
+		return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+	}
+}
+
+var functions = map[string]*function{
+	// Strings
+	"lower":      {1, 1, stringFunc(strings.ToLower)},
+	"upper":      {1, 1, stringFunc(strings.ToUpper)},
+	"trim":       {1, 1, stringFunc(strings.TrimSpace)},
+	"startsWith": {2, 2, stringPredicate(strings.HasPrefix)},
+	"endsWith":   {2, 2, stringPredicate(strings.HasSuffix)},
+	"replace":    {3, 3, fnReplace},
+	"substr":     {2, 3, fnSubstr},
+	"split":      {2, 2, fnSplit},
+	"join":       {2, 2, fnJoin},
+	"matches":    {2, 2, fnMatches},
+	"string":     {1, 1, func(args []interface{}) (interface{}, error) { return toString(args[0]), nil }},
+
+	// Strings and arrays
+	"len":      {1, 1, fnLen},
+	"contains": {2, 2, fnContains},
+
+	// Numbers
+	"number": {1, 1, fnNumber},
+	"round":  {1, 2, fnRound},
+	"floor":  {1, 1, mathFunc(math.Floor)},
+	"ceil":   {1, 1, mathFunc(math.Ceil)},
+	"abs":    {1, 1, mathFunc(math.Abs)},
+	"min":    {1, -1, fnMin},
+	"max":    {1, -1, fnMax},
+
+	// Dates, as Unix timestamps in seconds
+	"now":        {0, 0, fnNow},
+	"date":       {1, 1, fnDate},
+	"formatDate": {2, 2, fnFormatDate},
+	"hours":      {1, 1, scaleFunc(3600)},
+	"days":       {1, 1, scaleFunc(86400)},
+
+	// Null handling
+	"coalesce": {1, -1, fnCoalesce},
+}
+
+func stringFunc(f func(string) string) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		return f(toString(args[0])), nil
+	}
+}
+
+func stringPredicate(f func(s, t string) bool) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		return f(toString(args[0]), toString(args[1])), nil
+	}
+}
+
+func mathFunc(f func(float64) float64) func([]interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		n, err := number(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return f(n), nil
+	}
+}
+
+func scaleFunc(factor float64) func([]interface{}) (interface{}, error) {
+	return mathFunc(func(n float64) float64 { return n * factor })
+}
+
+// number requires a numeric argument.
+func number(v interface{}) (float64, error) {
+	n, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("expected a number, got %s", typeName(v))
+	}
+	return n, nil
+}
+
+func fnReplace(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	s, old, replacement := toString(args[0]), toString(args[1]), toString(args[2])
+
+	// Check the size first: replacing "" inserts between every character
+	n := strings.Count(s, old)
+	if len(s)+n*(len(replacement)-len(old)) > maxLen {
+		return nil, errTooLong
+	}
+	return strings.ReplaceAll(s, old, replacement), nil
+}
+
+// fnSubstr returns the characters from start, up to length of them if
+// given. A negative start counts from the end.
+func fnSubstr(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	runes := []rune(toString(args[0]))
+
+	start, err := number(args[1])
+	if err != nil {
+		return nil, err
+	}
+	from := int(start)
+	if from < 0 {
+		from = max(len(runes)+from, 0)
+	}
+	from = min(from, len(runes))
+
+	to := len(runes)
+	if len(args) == 3 {
+		length, err := number(args[2])
+		if err != nil {
+			return nil, err
+		}
+		// Compared as floats, so huge lengths can't overflow
+		if length < float64(to-from) {
+			to = from + max(int(length), 0)
+		}
+	}
+
+	return string(runes[from:to]), nil
+}
+
+func fnSplit(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	parts := strings.Split(toString(args[0]), toString(args[1]))
+	result := make([]interface{}, len(parts))
+	for i, p := range parts {
+		result[i] = p
+	}
+	return result, nil
+}
+
+func fnJoin(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	items, ok := args[0].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an array, got %s", typeName(args[0]))
+	}
+	sep := toString(args[1])
+	parts := make([]string, len(items))
+	size := 0
+	for i, item := range items {
+		parts[i] = toString(normalize(item))
+		if size += len(parts[i]) + len(sep); size > maxLen+len(sep) {
+			return nil, errTooLong
+		}
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func fnMatches(args []interface{}) (interface{}, error) {
+	re, err := regexp.Compile(toString(args[1]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re.MatchString(toString(args[0])), nil
+}
+
+func fnLen(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return 0.0, nil
+	case string:
+		return float64(len([]rune(v))), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("expected a string, array or object, got %s", typeName(args[0]))
+}
+
+// fnContains tests for a substring in a string, an element in an array or
+// a key in an object.
+func fnContains(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return false, nil
+	case []interface{}:
+		for _, item := range v {
+			if equal(normalize(item), args[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		_, ok := v[toString(args[1])]
+		return ok, nil
+	}
+	return strings.Contains(toString(args[0]), toString(args[1])), nil
+}
+
+// fnNumber converts a value to a number, or null if it isn't one.
+func fnNumber(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1.0, nil
+		}
+		return 0.0, nil
+	case string:
+		if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func fnRound(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	n, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	digits := 0.0
+	if len(args) == 2 {
+		if digits, err = number(args[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	scale := math.Pow(10, digits)
+	return math.Round(n*scale) / scale, nil
+}
+
+func fnMin(args []interface{}) (interface{}, error) {
+	return extreme(args, func(a, b float64) bool { return a < b })
+}
+
+func fnMax(args []interface{}) (interface{}, error) {
+	return extreme(args, func(a, b float64) bool { return a > b })
+}
+
+// extreme returns the number that wins over all others, ignoring nulls. A
+// single array argument is treated as the list of numbers.
+func extreme(args []interface{}, better func(a, b float64) bool) (interface{}, error) {
+	if len(args) == 1 {
+		if items, ok := args[0].([]interface{}); ok {
+			args = make([]interface{}, len(items))
+			for i, item := range items {
+				args[i] = normalize(item)
+			}
+		}
+	}
+
+	var result interface{}
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		n, err := number(arg)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil || better(n, result.(float64)) {
+			result = n
+		}
+	}
+	return result, nil
+}
+
+func fnNow(args []interface{}) (interface{}, error) {
+	return float64(time.Now().Unix()), nil
+}
+
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// fnDate parses a date string to a Unix timestamp. Numbers are taken to be
+// timestamps already; anything unparseable is null.
+func fnDate(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return float64(t.Unix()), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// fnFormatDate formats a Unix timestamp in UTC with a Go time layout, such
+// as "2006-01-02".
+func fnFormatDate(args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	ts, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return time.Unix(int64(ts), 0).UTC().Format(toString(args[1])), nil
+}
+
+func fnCoalesce(args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
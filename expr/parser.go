@@ -0,0 +1,294 @@
+package expr
+
+import (
+	"fmt"
+)
+
+const (
+	// maxLength and maxDepth bound the size of an expression, so a config
+	// can't make the parser or evaluator use unbounded stack.
+	maxLength = 4096
+	maxDepth  = 64
+)
+
+// Binding powers, lowest first
+const (
+	bpNone = iota
+	bpConditional
+	bpCoalesce
+	bpOr
+	bpAnd
+	bpEquality
+	bpComparison
+	bpSum
+	bpProduct
+	bpPrefix
+	bpPostfix
+)
+
+// infixPower returns the binding power of an infix or postfix operator, or
+// bpNone if tok doesn't continue an expression.
+func infixPower(tok token) int {
+	switch tok.kind {
+	case tokOp:
+		switch tok.text {
+		case "?":
+			return bpConditional
+		case "??":
+			return bpCoalesce
+		case "||":
+			return bpOr
+		case "&&":
+			return bpAnd
+		case "==", "!=":
+			return bpEquality
+		case "<", "<=", ">", ">=":
+			return bpComparison
+		case "+", "-":
+			return bpSum
+		case "*", "/", "%":
+			return bpProduct
+		case ".", "[":
+			return bpPostfix
+		}
+	case tokIdent:
+		switch tok.text {
+		case "or":
+			return bpOr
+		case "and":
+			return bpAnd
+		}
+	}
+	return bpNone
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func parse(src string) (node, error) {
+	if len(src) > maxLength {
+		return nil, &SyntaxError{Pos: maxLength, Msg: fmt.Sprintf("expression longer than %d characters", maxLength)}
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, &SyntaxError{Pos: 0, Msg: "empty expression"}
+	}
+
+	n, err := p.parseExpr(bpNone)
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", describe(tok))}
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(op string) error {
+	tok := p.next()
+	if tok.kind != tokOp || tok.text != op {
+		return &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("expected %q, found %s", op, describe(tok))}
+	}
+	return nil
+}
+
+// parseExpr parses an expression whose operators bind tighter than minBP.
+func (p *parser) parseExpr(minBP int) (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, &SyntaxError{Pos: p.peek().pos, Msg: "expression nested too deeply"}
+	}
+
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		bp := infixPower(tok)
+		if bp <= minBP {
+			return left, nil
+		}
+		p.next()
+
+		if left, err = p.parseInfix(left, tok, bp); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parsePrefix() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokNumber:
+		return &literal{value: tok.num}, nil
+
+	case tokString:
+		return &literal{value: tok.text}, nil
+
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return &literal{value: true}, nil
+		case "false":
+			return &literal{value: false}, nil
+		case "null":
+			return &literal{value: nil}, nil
+		case "not":
+			return p.parseUnary("!")
+		case "and", "or":
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+		}
+
+		if next := p.peek(); next.kind == tokOp && next.text == "(" {
+			return p.parseCall(tok)
+		}
+		return &field{name: tok.text}, nil
+
+	case tokOp:
+		switch tok.text {
+		case "(":
+			n, err := p.parseExpr(bpNone)
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "!", "-":
+			return p.parseUnary(tok.text)
+		}
+	}
+
+	return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", describe(tok))}
+}
+
+func (p *parser) parseUnary(op string) (node, error) {
+	x, err := p.parseExpr(bpPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return &unary{op: op, x: x}, nil
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("unknown function %q", name.text)}
+	}
+	p.next() // (
+
+	var args []node
+	if tok := p.peek(); tok.kind != tokOp || tok.text != ")" {
+		for {
+			arg, err := p.parseExpr(bpNone)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if tok := p.peek(); tok.kind == tokOp && tok.text == "," {
+				p.next()
+				continue
+			}
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("%s() takes %s, got %d", name.text, fn.arity(), len(args))}
+	}
+
+	return &call{name: name.text, fn: fn, args: args}, nil
+}
+
+func (p *parser) parseInfix(left node, tok token, bp int) (node, error) {
+	op := tok.text
+	switch op {
+	case "and":
+		op = "&&"
+	case "or":
+		op = "||"
+	}
+
+	switch op {
+	case ".":
+		name := p.next()
+		if name.kind != tokIdent {
+			return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("expected field name after \".\", found %s", describe(name))}
+		}
+		return &index{x: left, key: &literal{value: name.text}}, nil
+
+	case "[":
+		key, err := p.parseExpr(bpNone)
+		if err != nil {
+			return nil, err
+		}
+		return &index{x: left, key: key}, p.expect("]")
+
+	case "?":
+		then, err := p.parseExpr(bpNone)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		// Right-associative, so a ? b : c ? d : e nests to the right
+		otherwise, err := p.parseExpr(bpConditional - 1)
+		if err != nil {
+			return nil, err
+		}
+		return &conditional{cond: left, then: then, otherwise: otherwise}, nil
+
+	case "&&", "||", "??":
+		right, err := p.parseExpr(bp)
+		if err != nil {
+			return nil, err
+		}
+		return &logical{op: op, left: left, right: right}, nil
+	}
+
+	right, err := p.parseExpr(bp)
+	if err != nil {
+		return nil, err
+	}
+	return &binary{op: op, left: left, right: right}, nil
+}
+
+func describe(tok token) string {
+	switch tok.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return fmt.Sprintf("string %q", tok.text)
+	default:
+		return fmt.Sprintf("%q", tok.text)
+	}
+}
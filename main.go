@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -53,6 +54,8 @@ func main() {
 		serve(configPath)
 	case "init":
 		initConfig()
+	case "db":
+		dbCommand(os.Args[2:])
 	case "help", "--help", "-h":
 		printUsage()
 	case "version", "--version", "-v":
@@ -73,16 +76,25 @@ func printUsage() {
 	fmt.Println("Commands:")
 	fmt.Println("  serve              Start the server")
 	fmt.Println("  init [path]        Create a sample config file (default: config.yaml)")
+	fmt.Println("  db prune           Delete old executions and their logs")
 	fmt.Println("  version            Show version information")
 	fmt.Println("  help               Show this help message")
 	fmt.Println()
 	fmt.Println("Serve Flags:")
 	fmt.Println("  -c, --config PATH  Path to config file (optional, uses .env if not specified)")
 	fmt.Println()
+	fmt.Println("Prune Flags:")
+	fmt.Println("  -c, --config PATH     Path to config file")
+	fmt.Println("  --keep N              Newest executions to keep per pipe (default: keep_executions)")
+	fmt.Println("  --max-age-days N      Delete executions older than N days (default: execution_max_age_days)")
+	fmt.Println("  --dry-run             Count what would be deleted without deleting it")
+	fmt.Println("  --vacuum              Shrink the database file afterwards")
+	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  pipes init")
 	fmt.Println("  pipes serve -c config.yaml")
 	fmt.Println("  pipes serve                    # Uses .env file")
+	fmt.Println("  pipes db prune --keep 20 --vacuum")
 	fmt.Println()
 }
 
@@ -111,17 +123,36 @@ func serve(configPath string) {
 	}
 	defer db.Close()
 
+	db.SetLogStorage(store.LogStorage{MaxPayload: cfg.MaxLogPayload, Compress: cfg.CompressLogs})
+
 	logger.Info("database initialized successfully")
 
+	// Executions left running by a previous process can't be resumed
+	if n, err := db.CancelOrphanedExecutions("interrupted by server restart"); err != nil {
+		logger.Error("failed to cancel orphaned executions", "error", err)
+	} else if n > 0 {
+		logger.Warn("cancelled orphaned executions", "count", n)
+	}
+
+	// Initialize executor (shared so the worker limit applies globally)
+	executor := engine.NewExecutor(db, cfg.MaxWorkers)
+	manager := engine.NewManager(executor)
+
 	// Initialize scheduler
-	scheduler := engine.NewScheduler(db, logger)
+	scheduler := engine.NewScheduler(db, manager, logger)
+	if opts := retention(cfg); opts.KeepPerPipe > 0 || opts.MaxAge > 0 {
+		scheduler.SetRetention(opts)
+		logger.Info("pruning old executions", "keep_per_pipe", opts.KeepPerPipe, "max_age", opts.MaxAge)
+	} else {
+		logger.Info("keeping all executions; set keep_executions or execution_max_age_days to prune them")
+	}
 	scheduler.Start()
 	defer scheduler.Stop()
 
 	logger.Info("scheduler started")
 
 	// Initialize web server
-	server := web.NewServer(cfg, db, logger)
+	server := web.NewServer(cfg, db, manager, logger)
 
 	// Start server in goroutine
 	serverErr := make(chan error, 1)
@@ -151,9 +182,82 @@ func serve(configPath string) {
 		logger.Error("server shutdown error", "error", err)
 	}
 
+	// Cancel running executions so they record their status
+	if err := manager.Shutdown(ctx); err != nil {
+		logger.Error("execution shutdown error", "error", err)
+	}
+
 	logger.Info("shutdown complete")
 }
 
+// retention returns the configured limits on execution history.
+func retention(cfg *config.Config) store.PruneOptions {
+	return store.PruneOptions{
+		KeepPerPipe: cfg.KeepExecutions,
+		MaxAge:      time.Duration(cfg.ExecutionMaxAgeDays) * 24 * time.Hour,
+	}
+}
+
+func dbCommand(args []string) {
+	if len(args) == 0 || args[0] != "prune" {
+		fmt.Println("Usage: pipes db prune [flags]")
+		os.Exit(1)
+	}
+
+	flags := flag.NewFlagSet("db prune", flag.ExitOnError)
+	var configPath string
+	flags.StringVar(&configPath, "c", "", "path to config file")
+	flags.StringVar(&configPath, "config", "", "path to config file")
+	keep := flags.Int("keep", -1, "newest executions to keep per pipe")
+	maxAgeDays := flags.Int("max-age-days", -1, "delete executions older than this many days")
+	dryRun := flags.Bool("dry-run", false, "count what would be deleted without deleting it")
+	vacuum := flags.Bool("vacuum", false, "shrink the database file afterwards")
+	flags.Parse(args[1:])
+
+	cfg, err := config.Read(configPath)
+	if err != nil {
+		logger.Fatal("failed to load config", "error", err)
+	}
+
+	opts := retention(cfg)
+	if *keep >= 0 {
+		opts.KeepPerPipe = *keep
+	}
+	if *maxAgeDays >= 0 {
+		opts.MaxAge = time.Duration(*maxAgeDays) * 24 * time.Hour
+	}
+	opts.DryRun = *dryRun
+
+	if opts.KeepPerPipe == 0 && opts.MaxAge == 0 {
+		fmt.Println("No retention limits set; use --keep or --max-age-days, or set them in the config")
+		os.Exit(1)
+	}
+
+	db, err := store.New(cfg.DatabasePath)
+	if err != nil {
+		logger.Fatal("failed to open database", "error", err)
+	}
+	defer db.Close()
+
+	result, err := db.PruneExecutions(opts)
+	if err != nil {
+		logger.Fatal("failed to prune executions", "error", err)
+	}
+
+	if *dryRun {
+		fmt.Printf("Would delete %d executions and %d log rows\n", result.Executions, result.Logs)
+		return
+	}
+	fmt.Printf("✓ Deleted %d executions and %d log rows\n", result.Executions, result.Logs)
+
+	if *vacuum {
+		if err := db.Vacuum(); err != nil {
+			logger.Fatal("failed to vacuum database", "error", err)
+		}
+		fmt.Println("✓ Database vacuumed")
+	}
+}
+
 func initConfig() {
 	configPath := "config.yaml"
 	if len(os.Args) > 2 {
@@ -185,6 +289,15 @@ log_level: info  # debug, info, warn, error, fatal
 # Database
 db_path: pipes.db
 
+# Execution
+max_workers: 8  # nodes running at once across all pipes
+
+# Execution history (0 turns a limit off)
+keep_executions: 0            # newest executions kept per pipe, e.g. 100
+execution_max_age_days: 0     # delete executions older than this
+max_log_payload: 1048576      # bytes of output stored per log row
+compress_logs: false          # gzip stored node output
+
 # OAuth (Indiko)
 # Set these environment variables or replace with actual values:
 indiko_url: ${INDIKO_URL}
@@ -0,0 +1,24 @@
+package nodes
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ConfigNumber reads a numeric config value. The editor stores field values
+// as strings, so numeric strings are accepted too.
+func ConfigNumber(config map[string]interface{}, key string) (float64, bool) {
+	switch v := config[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,31 @@
+package nodes
+
+import "errors"
+
+// TransientError marks a failure that may succeed if retried, such as a
+// network error or an HTTP 5xx response from an upstream server.
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string { return e.Err.Error() }
+func (e *TransientError) Unwrap() error { return e.Err }
+
+// Transient wraps err as a TransientError. It returns nil if err is nil.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err}
+}
+
+// IsTransient reports whether any error in err's chain is a TransientError.
+func IsTransient(err error) bool {
+	var t *TransientError
+	return errors.As(err, &t)
+}
+
+// IsTransientStatus reports whether an HTTP status is worth retrying.
+func IsTransientStatus(code int) bool {
+	return code >= 500 || code == 429 || code == 408
+}
@@ -0,0 +1,193 @@
+package nodes
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kierank/pipes/store"
+)
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Where a FetchResult's body came from.
+const (
+	FromNetwork     = "network"
+	FromCache       = "cache"
+	FromRevalidated = "revalidated"
+)
+
+type FetchResult struct {
+	Body   []byte
+	Source string
+}
+
+// Fetch performs a GET request through the node's source_cache entry for the
+// request URL. Fresh data is returned without contacting upstream; stale
+// data is revalidated with If-None-Match/If-Modified-Since and reused on a
+// 304. Responses stay fresh for whatever Cache-Control or Expires allows,
+// but at least minRefresh. Previews that ask for cached data get it however
+// old it is, and never write to the cache.
+func (c *Context) Fetch(req *http.Request, minRefresh time.Duration) (*FetchResult, error) {
+	cacheKey := req.URL.String()
+	now := time.Now()
+
+	var cached *store.SourceCache
+	if c.DB != nil && c.NodeID != "" {
+		cached, _ = c.DB.GetSourceCache(c.PipeID, c.NodeID, cacheKey)
+	}
+
+	if cached != nil {
+		if now.Unix() < cached.ExpiresAt || c.UseStaleCache() {
+			return &FetchResult{Body: []byte(cached.Data), Source: FromCache}, nil
+		}
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	if c.offline() {
+		return nil, ErrOffline
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, Transient(fmt.Errorf("fetch: %w", err))
+	}
+	defer resp.Body.Close()
+
+	lifetime, storable := cacheLifetime(resp.Header, now)
+	if lifetime < minRefresh {
+		lifetime = minRefresh
+	}
+	expiresAt := now.Add(lifetime).Unix()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" {
+			etag = cached.ETag
+		}
+		if lastModified == "" {
+			lastModified = cached.LastModified
+		}
+		if c.Preview == nil {
+			c.DB.RefreshSourceCache(cached.ID, etag, lastModified, expiresAt)
+		}
+		return &FetchResult{Body: []byte(cached.Data), Source: FromRevalidated}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("HTTP %d", resp.StatusCode)
+		if IsTransientStatus(resp.StatusCode) {
+			return nil, Transient(err)
+		}
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, Transient(fmt.Errorf("read body: %w", err))
+	}
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if c.DB != nil && c.NodeID != "" && c.Preview == nil && storable && (etag != "" || lastModified != "" || lifetime > 0) {
+		c.DB.SaveSourceCache(&store.SourceCache{
+			PipeID:       c.PipeID,
+			NodeID:       c.NodeID,
+			CacheKey:     cacheKey,
+			Data:         string(body),
+			ETag:         etag,
+			LastModified: lastModified,
+			ExpiresAt:    expiresAt,
+		})
+	}
+
+	return &FetchResult{Body: body, Source: FromNetwork}, nil
+}
+
+// Send performs a request without caching, for requests such as POSTs
+// whose responses can't be reused. Failures are classified like Fetch's.
+// Previews only send GETs and HEADs (see ErrPreviewSend).
+func (c *Context) Send(req *http.Request) ([]byte, error) {
+	if c.offline() {
+		return nil, ErrOffline
+	}
+	if c.Preview != nil && !safeMethod(req.Method) {
+		return nil, ErrPreviewSend
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, Transient(fmt.Errorf("fetch: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("HTTP %d", resp.StatusCode)
+		if IsTransientStatus(resp.StatusCode) {
+			return nil, Transient(err)
+		}
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, Transient(fmt.Errorf("read body: %w", err))
+	}
+	return body, nil
+}
+
+// safeMethod reports whether a request method only reads data.
+func safeMethod(method string) bool {
+	return method == "" || method == http.MethodGet || method == http.MethodHead
+}
+
+// cacheLifetime reports how long a response may be reused without
+// revalidation, and whether it may be stored at all.
+func cacheLifetime(header http.Header, now time.Time) (time.Duration, bool) {
+	if cc := header.Get("Cache-Control"); cc != "" {
+		var maxAge time.Duration
+		hasMaxAge := false
+
+		for _, directive := range strings.Split(cc, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			switch {
+			case directive == "no-store":
+				return 0, false
+			case directive == "no-cache":
+				return 0, true
+			case strings.HasPrefix(directive, "max-age="):
+				if secs, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil && secs > 0 {
+					maxAge = time.Duration(secs) * time.Second
+					hasMaxAge = true
+				}
+			}
+		}
+
+		if hasMaxAge {
+			return maxAge, true
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			// Invalid dates such as "0" mean already expired
+			return 0, true
+		}
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			now = date
+		}
+		if t.After(now) {
+			return t.Sub(now), true
+		}
+	}
+
+	return 0, true
+}
@@ -0,0 +1,36 @@
+package nodes
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+// ParseHTML parses a fetched page, converting it to UTF-8 from the encoding
+// its meta tags declare.
+func ParseHTML(body []byte) (*goquery.Document, error) {
+	r, err := charset.NewReader(bytes.NewReader(body), "")
+	if err != nil {
+		return nil, fmt.Errorf("decode page: %w", err)
+	}
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse page: %w", err)
+	}
+	return doc, nil
+}
+
+// PageBase returns the URL a page's relative links are resolved against,
+// honouring a <base href>.
+func PageBase(doc *goquery.Document, pageURL *url.URL) *url.URL {
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := pageURL.Parse(strings.TrimSpace(href)); err == nil {
+			return u
+		}
+	}
+	return pageURL
+}
@@ -3,6 +3,7 @@ package nodes
 import (
 	"context"
 
+	"github.com/kierank/pipes/events"
 	"github.com/kierank/pipes/store"
 )
 
@@ -22,6 +23,46 @@ type Node interface {
 	GetConfigSchema() *ConfigSchema
 }
 
+// MultiOutputNode is implemented by nodes that emit several named outputs,
+// such as a "matched"/"unmatched" split. A connection picks a port with its
+// sourceHandle; an empty handle selects the first port. Execute should
+// return the first port's items.
+type MultiOutputNode interface {
+	Node
+
+	OutputPorts(config map[string]interface{}) []string
+
+	ExecutePorts(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *Context) (map[string][]interface{}, error)
+}
+
+// MultiInputNode is implemented by nodes whose inputs play different roles,
+// such as the left and right side of a join. inputs[i] holds the items
+// connected to port i (empty if nothing is connected); a connection picks
+// a port with its targetHandle, and an empty handle selects the first port.
+type MultiInputNode interface {
+	Node
+
+	InputPorts() []string
+}
+
+// OutputPorts returns the output port names of a node for the given config,
+// or nil for nodes with a single unnamed output.
+func OutputPorts(node Node, config map[string]interface{}) []string {
+	if m, ok := node.(MultiOutputNode); ok {
+		return m.OutputPorts(config)
+	}
+	return nil
+}
+
+// InputPorts returns the input port names of a node, or nil for nodes whose
+// inputs are positional.
+func InputPorts(node Node) []string {
+	if m, ok := node.(MultiInputNode); ok {
+		return m.InputPorts()
+	}
+	return nil
+}
+
 type ConfigSchema struct {
 	Fields []ConfigField `json:"fields"`
 }
@@ -45,7 +86,34 @@ type FieldOption struct {
 type Context struct {
 	ExecutionID string
 	PipeID      string
+	NodeID      string
 	DB          *store.DB
+
+	// TriggerType is how the run was started (manual, scheduled, webhook,
+	// ...). TriggerPayload holds the data it was started with, if any.
+	TriggerType    string
+	TriggerPayload interface{}
+
+	// Events receives a copy of every log entry, if set
+	Events *events.Bus
+
+	// OwnerID is the user who owns the pipe being run
+	OwnerID string
+
+	// Pipes runs other pipes for sub-pipe nodes, if set
+	Pipes PipeRunner
+
+	// Call is set when the pipe was run by another pipe. CallStack lists
+	// the pipes in the current chain of calls, outermost first and ending
+	// with this one.
+	Call      *PipeCall
+	CallStack []string
+
+	// Preview is set for preview runs, which must not have side effects
+	Preview *Preview
+
+	// Shared by the per-node copies made with ForNode
+	state *stateChanges
 }
 
 func NewContext(executionID, pipeID string, db *store.DB) *Context {
@@ -53,13 +121,53 @@ func NewContext(executionID, pipeID string, db *store.DB) *Context {
 		ExecutionID: executionID,
 		PipeID:      pipeID,
 		DB:          db,
+		state:       &stateChanges{},
 	}
 }
 
+// ForNode returns a copy of the context for the node being executed, so
+// per-node state such as the source cache is keyed by its ID.
+func (c *Context) ForNode(nodeID string) *Context {
+	nc := *c
+	nc.NodeID = nodeID
+	return &nc
+}
+
 func (c *Context) Log(nodeID, level, message string) {
-	c.DB.LogExecution(c.ExecutionID, nodeID, level, message)
+	if c.Preview == nil {
+		c.DB.LogExecution(c.ExecutionID, nodeID, level, message)
+	}
+
+	// Stream under the executing node's ID so clients can place the entry
+	if c.NodeID != "" {
+		nodeID = c.NodeID
+	}
+
+	if c.Preview != nil {
+		c.Preview.Log(nodeID, level, message)
+		return
+	}
+	c.Events.Publish(events.Event{
+		Type:        events.TypeLog,
+		ExecutionID: c.ExecutionID,
+		NodeID:      nodeID,
+		Level:       level,
+		Message:     message,
+	})
 }
 
+// SaveOutput publishes the pipe's output. Runs made by another pipe don't
+// replace the pipe's own published output unless the call asks to, and
+// previews never do.
 func (c *Context) SaveOutput(format, content, contentType string) error {
+	if !c.PublishesOutput() {
+		return nil
+	}
 	return c.DB.SavePipeOutput(c.PipeID, format, content, contentType)
 }
+
+// PublishesOutput reports whether SaveOutput saves the output, rather than
+// skipping it for a preview or a call that doesn't publish.
+func (c *Context) PublishesOutput() bool {
+	return c.Preview == nil && (c.Call == nil || c.Call.Publish)
+}
@@ -3,6 +3,7 @@ package outputs
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/kierank/pipes/nodes"
 )
@@ -33,13 +34,21 @@ func (n *JSONOutputNode) Execute(ctx context.Context, config map[string]interfac
 		return nil, err
 	}
 
-	// Save output to database for public access
-	if err := execCtx.SaveOutput("json", string(jsonData), "application/json"); err != nil {
-		execCtx.Log("json-output", "error", "Failed to save output: "+err.Error())
+	// Save output to database for public access. The document is in
+	// pipe_outputs, so only its size is logged.
+	switch {
+	case execCtx.Preview != nil:
+		execCtx.Log("json-output", "info", fmt.Sprintf("Would save %d items (%d bytes)", len(data), len(jsonData)))
+	case !execCtx.PublishesOutput():
+		execCtx.Log("json-output", "info", fmt.Sprintf("Didn't save %d items (%d bytes): the calling pipe doesn't publish this pipe's output", len(data), len(jsonData)))
+	default:
+		if err := execCtx.SaveOutput("json", string(jsonData), "application/json"); err != nil {
+			execCtx.Log("json-output", "error", "Failed to save output: "+err.Error())
+		} else {
+			execCtx.Log("json-output", "info", fmt.Sprintf("Saved %d items (%d bytes)", len(data), len(jsonData)))
+		}
 	}
 
-	execCtx.Log("json-output", "info", string(jsonData))
-
 	return data, nil
 }
 
@@ -107,13 +107,21 @@ func (n *RSSOutputNode) Execute(ctx context.Context, config map[string]interface
 
 	rssOutput := fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n%s", string(xmlData))
 
-	// Save output to database for public access
-	if err := execCtx.SaveOutput("rss", rssOutput, "application/rss+xml"); err != nil {
-		execCtx.Log("rss-output", "error", "Failed to save output: "+err.Error())
+	// Save output to database for public access. The document is in
+	// pipe_outputs, so only its size is logged.
+	switch {
+	case execCtx.Preview != nil:
+		execCtx.Log("rss-output", "info", fmt.Sprintf("Would save %d items (%d bytes)", len(items), len(rssOutput)))
+	case !execCtx.PublishesOutput():
+		execCtx.Log("rss-output", "info", fmt.Sprintf("Didn't save %d items (%d bytes): the calling pipe doesn't publish this pipe's output", len(items), len(rssOutput)))
+	default:
+		if err := execCtx.SaveOutput("rss", rssOutput, "application/rss+xml"); err != nil {
+			execCtx.Log("rss-output", "error", "Failed to save output: "+err.Error())
+		} else {
+			execCtx.Log("rss-output", "info", fmt.Sprintf("Saved %d items (%d bytes)", len(items), len(rssOutput)))
+		}
 	}
 
-	execCtx.Log("rss-output", "info", rssOutput)
-
 	return data, nil
 }
 
@@ -34,6 +34,11 @@ func (n *WebhookOutputNode) Execute(ctx context.Context, config map[string]inter
 
 	data := inputs[0]
 
+	if execCtx.Preview != nil {
+		execCtx.Log("webhook-output", "info", fmt.Sprintf("Would post %d items to %s", len(data), url))
+		return data, nil
+	}
+
 	payload := map[string]interface{}{
 		"count": len(data),
 		"items": data,
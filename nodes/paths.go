@@ -0,0 +1,32 @@
+package nodes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExtractPath follows a dot-notation path such as "data.items" or
+// "results.0.name" into decoded JSON. Numeric parts index into arrays. It
+// returns nil if the path doesn't exist.
+func ExtractPath(data interface{}, path string) interface{} {
+	parts := strings.Split(path, ".")
+	current := data
+
+	for _, part := range parts {
+		if m, ok := current.(map[string]interface{}); ok {
+			current = m[part]
+		} else if arr, ok := current.([]interface{}); ok {
+			// Try to access array by index if part is numeric
+			var idx int
+			if _, err := fmt.Sscanf(part, "%d", &idx); err == nil && idx >= 0 && idx < len(arr) {
+				current = arr[idx]
+			} else {
+				return nil
+			}
+		} else {
+			return nil
+		}
+	}
+
+	return current
+}
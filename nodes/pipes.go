@@ -0,0 +1,56 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kierank/pipes/store"
+)
+
+// DefaultPipeInput is the name of a pipe-input node that doesn't set one.
+const DefaultPipeInput = "input"
+
+// PipeRunner runs another pipe on behalf of a node, such as the sub-pipe
+// node. It is implemented by the executor.
+type PipeRunner interface {
+	// RunPipe runs a pipe as a child of the caller's execution and returns
+	// the output of its last node.
+	RunPipe(ctx context.Context, caller *Context, call PipeCall) ([]interface{}, error)
+}
+
+// PipeCall describes a run of a pipe from another pipe.
+type PipeCall struct {
+	PipeID string
+
+	// Input names the pipe-input nodes that receive Items
+	Input string
+	Items []interface{}
+
+	// TriggerType is recorded on the child execution, "sub-pipe" if empty
+	TriggerType string
+
+	// Publish lets the pipe's output nodes save its output, as in a run of
+	// its own
+	Publish bool
+}
+
+// LoadPipe returns a pipe that the current pipe's owner may use: one of
+// their own, or a public one.
+func (c *Context) LoadPipe(pipeID string) (*store.Pipe, error) {
+	pipe, err := c.DB.GetPipe(pipeID)
+	if err != nil {
+		return nil, fmt.Errorf("get pipe: %w", err)
+	}
+	if pipe == nil || (pipe.UserID != c.OwnerID && !pipe.IsPublic) {
+		return nil, fmt.Errorf("pipe not found: %s", pipeID)
+	}
+	return pipe, nil
+}
+
+// RunPipe runs another pipe as part of this execution.
+func (c *Context) RunPipe(ctx context.Context, call PipeCall) ([]interface{}, error) {
+	if c.Pipes == nil {
+		return nil, fmt.Errorf("running other pipes is not supported here")
+	}
+	return c.Pipes.RunPipe(ctx, c, call)
+}
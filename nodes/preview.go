@@ -0,0 +1,62 @@
+package nodes
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrOffline is returned for requests made during an offline preview.
+var ErrOffline = errors.New("requests are disabled while replaying")
+
+// ErrPreviewSend is returned for requests other than GETs and HEADs made
+// during a preview, which could change data elsewhere.
+var ErrPreviewSend = errors.New("only GET requests are sent in previews")
+
+// Preview is set on the context of a preview run, which shows what nodes
+// would produce without side effects: outputs aren't saved or delivered,
+// node state and the source cache aren't written, Send refuses requests
+// other than GETs, and log entries are collected here rather than stored
+// with an execution.
+type Preview struct {
+	// UseCache serves sources from the source cache whenever it has an
+	// entry, however old, instead of fetching again.
+	UseCache bool
+
+	// Offline previews make no requests: Fetch only serves cached data,
+	// however old, and Send fails with ErrOffline.
+	Offline bool
+
+	mu   sync.Mutex
+	logs []PreviewLog
+}
+
+type PreviewLog struct {
+	NodeID  string `json:"node_id"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// Log records a log entry for a node.
+func (p *Preview) Log(nodeID, level, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logs = append(p.logs, PreviewLog{NodeID: nodeID, Level: level, Message: message})
+}
+
+// Logs returns the entries logged so far, oldest first.
+func (p *Preview) Logs() []PreviewLog {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PreviewLog{}, p.logs...)
+}
+
+// UseStaleCache reports whether cached data should be used even once it
+// has expired.
+func (c *Context) UseStaleCache() bool {
+	return c.Preview != nil && (c.Preview.UseCache || c.Preview.Offline)
+}
+
+// offline reports whether requests are disabled.
+func (c *Context) offline() bool {
+	return c.Preview != nil && c.Preview.Offline
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -29,8 +28,6 @@ func (n *HTTPSourceNode) Execute(ctx context.Context, config map[string]interfac
 
 	execCtx.Log("http-source", "info", fmt.Sprintf("Fetching %s", url))
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
@@ -47,31 +44,22 @@ func (n *HTTPSourceNode) Execute(ctx context.Context, config map[string]interfac
 
 	req.Header.Set("User-Agent", "Pipes/1.0")
 
-	resp, err := client.Do(req)
+	result, err := execCtx.Fetch(req, minRefresh(config))
 	if err != nil {
-		return nil, fmt.Errorf("fetch: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read body: %w", err)
+		return nil, err
 	}
+	logCacheHit(execCtx, "http-source", result)
 
 	// Parse JSON
 	var data interface{}
-	if err := json.Unmarshal(body, &data); err != nil {
+	if err := json.Unmarshal(result.Body, &data); err != nil {
 		return nil, fmt.Errorf("parse JSON: %w", err)
 	}
 
 	// Extract items from a path if specified
 	itemsPath, _ := config["items_path"].(string)
 	if itemsPath != "" {
-		data = extractPath(data, itemsPath)
+		data = nodes.ExtractPath(data, itemsPath)
 	}
 
 	// Convert to array
@@ -94,27 +82,31 @@ func (n *HTTPSourceNode) Execute(ctx context.Context, config map[string]interfac
 	return items, nil
 }
 
-func extractPath(data interface{}, path string) interface{} {
-	parts := strings.Split(path, ".")
-	current := data
-
-	for _, part := range parts {
-		if m, ok := current.(map[string]interface{}); ok {
-			current = m[part]
-		} else if arr, ok := current.([]interface{}); ok {
-			// Try to access array by index if part is numeric
-			var idx int
-			if _, err := fmt.Sscanf(part, "%d", &idx); err == nil && idx < len(arr) {
-				current = arr[idx]
-			} else {
-				return nil
-			}
-		} else {
-			return nil
-		}
+// minRefresh reads a source's minimum refresh interval, in minutes.
+func minRefresh(config map[string]interface{}) time.Duration {
+	minutes, _ := nodes.ConfigNumber(config, "min_refresh")
+	if minutes <= 0 {
+		return 0
 	}
+	return time.Duration(minutes * float64(time.Minute))
+}
+
+func logCacheHit(execCtx *nodes.Context, nodeType string, result *nodes.FetchResult) {
+	switch result.Source {
+	case nodes.FromCache:
+		execCtx.Log(nodeType, "info", "Using cached response")
+	case nodes.FromRevalidated:
+		execCtx.Log(nodeType, "info", "Not modified, using cached response")
+	}
+}
 
-	return current
+var minRefreshField = nodes.ConfigField{
+	Name:        "min_refresh",
+	Label:       "Minimum Refresh (minutes)",
+	Type:        "number",
+	Required:    false,
+	Placeholder: "0",
+	HelpText:    "Reuse the cached response for at least this long, even if upstream allows less",
 }
 
 func (n *HTTPSourceNode) ValidateConfig(config map[string]interface{}) error {
@@ -160,6 +152,7 @@ func (n *HTTPSourceNode) GetConfigSchema() *nodes.ConfigSchema {
 				DefaultValue: 50,
 				HelpText:     "Maximum number of items",
 			},
+			minRefreshField,
 		},
 	}
 }
@@ -0,0 +1,154 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"github.com/kierank/pipes/nodes"
+	"github.com/kierank/pipes/store"
+)
+
+type PipeFeedNode struct{}
+
+func (n *PipeFeedNode) Type() string        { return "pipe-feed" }
+func (n *PipeFeedNode) Label() string       { return "Pipe Feed" }
+func (n *PipeFeedNode) Description() string { return "Read the saved output of another pipe" }
+func (n *PipeFeedNode) Category() string    { return "source" }
+func (n *PipeFeedNode) Inputs() int         { return 0 }
+func (n *PipeFeedNode) Outputs() int        { return 1 }
+
+func (n *PipeFeedNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	pipeID, _ := config["pipe_id"].(string)
+	pipeID = strings.TrimSpace(pipeID)
+	if pipeID == "" {
+		return nil, fmt.Errorf("pipe_id is required")
+	}
+
+	pipe, err := execCtx.LoadPipe(pipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := latestPipeOutput(execCtx.DB, pipe.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Re-run the pipe if it has no output yet or the output is too old
+	stale := output == nil
+	if minutes, ok := nodes.ConfigNumber(config, "max_age"); ok && minutes > 0 && output != nil {
+		age := time.Since(time.Unix(output.CreatedAt, 0))
+		stale = age > time.Duration(minutes*float64(time.Minute))
+	}
+
+	// Previews can't refresh the saved output, so they read it however old
+	// it is, or preview the pipe itself if it has none
+	if execCtx.Preview != nil && output == nil {
+		execCtx.Log("pipe-feed", "info", fmt.Sprintf("Previewing %q, which has no saved output", pipe.Name))
+		return execCtx.RunPipe(ctx, nodes.PipeCall{PipeID: pipe.ID, TriggerType: "pipe-feed"})
+	}
+
+	if stale && execCtx.Preview == nil {
+		execCtx.Log("pipe-feed", "info", fmt.Sprintf("Refreshing output of %q", pipe.Name))
+
+		_, err := execCtx.RunPipe(ctx, nodes.PipeCall{PipeID: pipe.ID, TriggerType: "pipe-feed", Publish: true})
+		if err != nil {
+			if output == nil || ctx.Err() != nil {
+				return nil, fmt.Errorf("refresh pipe: %w", err)
+			}
+			execCtx.Log("pipe-feed", "warn", fmt.Sprintf("Refresh failed, using saved output: %v", err))
+		} else if output, err = latestPipeOutput(execCtx.DB, pipe.ID); err != nil {
+			return nil, err
+		}
+
+		if output == nil {
+			return nil, fmt.Errorf("pipe %q has no JSON or RSS output", pipe.Name)
+		}
+	}
+
+	items, err := pipeOutputItems(output)
+	if err != nil {
+		return nil, fmt.Errorf("read %s output: %w", output.Format, err)
+	}
+
+	execCtx.Log("pipe-feed", "info", fmt.Sprintf("Read %d items from %q (%s output saved %s ago)", len(items), pipe.Name, output.Format, time.Since(time.Unix(output.CreatedAt, 0)).Round(time.Second)))
+
+	return items, nil
+}
+
+// latestPipeOutput returns a pipe's saved output, preferring JSON since it
+// keeps items as they were. It returns nil if the pipe has neither.
+func latestPipeOutput(db *store.DB, pipeID string) (*store.PipeOutput, error) {
+	for _, format := range []string{"json", "rss"} {
+		output, err := db.GetPipeOutput(pipeID, format)
+		if err != nil {
+			return nil, err
+		}
+		if output != nil {
+			return output, nil
+		}
+	}
+	return nil, nil
+}
+
+func pipeOutputItems(output *store.PipeOutput) ([]interface{}, error) {
+	if output.Format == "rss" {
+		feed, err := gofeed.NewParser().ParseString(output.Content)
+		if err != nil {
+			return nil, err
+		}
+		return feedItems(feed), nil
+	}
+
+	var data struct {
+		Items []interface{} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output.Content), &data); err != nil {
+		return nil, err
+	}
+	if data.Items == nil {
+		data.Items = []interface{}{}
+	}
+	return data.Items, nil
+}
+
+func (n *PipeFeedNode) ValidateConfig(config map[string]interface{}) error {
+	pipeID, _ := config["pipe_id"].(string)
+	if strings.TrimSpace(pipeID) == "" {
+		return fmt.Errorf("pipe_id is required")
+	}
+
+	if v, ok := config["max_age"]; ok && v != "" {
+		if minutes, ok := nodes.ConfigNumber(config, "max_age"); !ok || minutes < 0 {
+			return fmt.Errorf("max_age must be a non-negative number")
+		}
+	}
+	return nil
+}
+
+func (n *PipeFeedNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:        "pipe_id",
+				Label:       "Pipe ID",
+				Type:        "text",
+				Required:    true,
+				Placeholder: "ID from the pipe's editor URL",
+				HelpText:    "Pipe whose saved JSON or RSS output to read. It must be yours or public.",
+			},
+			{
+				Name:     "max_age",
+				Label:    "Max Age (minutes)",
+				Type:     "number",
+				Required: false,
+				HelpText: "Re-run the pipe first if its output is older than this (0 = use whatever is saved). A pipe with no output is always run.",
+			},
+		},
+	}
+}
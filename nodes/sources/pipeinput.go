@@ -0,0 +1,67 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+type PipeInputNode struct{}
+
+func (n *PipeInputNode) Type() string        { return "pipe-input" }
+func (n *PipeInputNode) Label() string       { return "Pipe Input" }
+func (n *PipeInputNode) Description() string { return "Items sent by a Sub-pipe node in another pipe" }
+func (n *PipeInputNode) Category() string    { return "source" }
+func (n *PipeInputNode) Inputs() int         { return 0 }
+func (n *PipeInputNode) Outputs() int        { return 1 }
+
+func (n *PipeInputNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	if execCtx.Call == nil {
+		execCtx.Log("pipe-input", "info", "Not called from another pipe, no items")
+		return []interface{}{}, nil
+	}
+
+	name := pipeInputName(config)
+	if name != execCtx.Call.Input {
+		execCtx.Log("pipe-input", "info", fmt.Sprintf("Caller sent items to input %q, not %q", execCtx.Call.Input, name))
+		return []interface{}{}, nil
+	}
+
+	items := execCtx.Call.Items
+	if items == nil {
+		items = []interface{}{}
+	}
+
+	execCtx.Log("pipe-input", "info", fmt.Sprintf("Received %d items", len(items)))
+	return items, nil
+}
+
+// PipeInputName returns the name a Pipe Input node is addressed by, or the
+// default name if none is set.
+func pipeInputName(config map[string]interface{}) string {
+	if name, _ := config["name"].(string); name != "" {
+		return name
+	}
+	return nodes.DefaultPipeInput
+}
+
+func (n *PipeInputNode) ValidateConfig(config map[string]interface{}) error {
+	return nil
+}
+
+func (n *PipeInputNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:         "name",
+				Label:        "Input Name",
+				Type:         "text",
+				Required:     false,
+				DefaultValue: nodes.DefaultPipeInput,
+				Placeholder:  nodes.DefaultPipeInput,
+				HelpText:     "Name that Sub-pipe nodes use to send items to this input",
+			},
+		},
+	}
+}
@@ -1,9 +1,10 @@
 package sources
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"time"
+	"net/http"
 
 	"github.com/mmcdole/gofeed"
 
@@ -27,14 +28,74 @@ func (n *RSSSourceNode) Execute(ctx context.Context, config map[string]interface
 
 	execCtx.Log("rss-source", "info", fmt.Sprintf("Fetching %s", url))
 
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Pipes/1.0")
+
+	result, err := execCtx.Fetch(req, minRefresh(config))
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	logCacheHit(execCtx, "rss-source", result)
+
 	// Parse feed
 	fp := gofeed.NewParser()
-	feed, err := fp.ParseURLWithContext(url, ctx)
+	feed, err := fp.Parse(bytes.NewReader(result.Body))
 	if err != nil {
 		return nil, fmt.Errorf("parse feed: %w", err)
 	}
 
-	// Convert feed items to generic interface{} slices
+	items := feedItems(feed)
+
+	// Apply limit if specified
+	if limit, ok := config["limit"].(float64); ok && limit > 0 {
+		if int(limit) < len(items) {
+			items = items[:int(limit)]
+		}
+	}
+
+	execCtx.Log("rss-source", "info", fmt.Sprintf("Retrieved %d items", len(items)))
+
+	return items, nil
+}
+
+func (n *RSSSourceNode) ValidateConfig(config map[string]interface{}) error {
+	url, ok := config["url"].(string)
+	if !ok || url == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	return nil
+}
+
+func (n *RSSSourceNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:        "url",
+				Label:       "Feed URL",
+				Type:        "url",
+				Required:    true,
+				Placeholder: "https://example.com/feed.xml",
+				HelpText:    "URL of the RSS or Atom feed",
+			},
+			{
+				Name:         "limit",
+				Label:        "Item Limit",
+				Type:         "number",
+				Required:     false,
+				DefaultValue: 50,
+				HelpText:     "Maximum number of items to fetch",
+			},
+			minRefreshField,
+		},
+	}
+}
+
+// feedItems converts a parsed feed's items to generic items.
+func feedItems(feed *gofeed.Feed) []interface{} {
 	var items []interface{}
 	for _, item := range feed.Items {
 		// Flatten author field - extract name if it's a Person struct
@@ -49,14 +110,14 @@ func (n *RSSSourceNode) Execute(ctx context.Context, config map[string]interface
 		if item.PublishedParsed != nil {
 			publishedAt = item.PublishedParsed.Unix()
 		} else if item.Published != "" {
-			if t, err := parseDate(item.Published); err == nil {
+			if t, err := nodes.ParseDate(item.Published); err == nil {
 				publishedAt = t.Unix()
 			}
 		}
 		if item.UpdatedParsed != nil {
 			updatedAt = item.UpdatedParsed.Unix()
 		} else if item.Updated != "" {
-			if t, err := parseDate(item.Updated); err == nil {
+			if t, err := nodes.ParseDate(item.Updated); err == nil {
 				updatedAt = t.Unix()
 			}
 		}
@@ -102,71 +163,5 @@ func (n *RSSSourceNode) Execute(ctx context.Context, config map[string]interface
 		})
 	}
 
-	// Apply limit if specified
-	if limit, ok := config["limit"].(float64); ok && limit > 0 {
-		if int(limit) < len(items) {
-			items = items[:int(limit)]
-		}
-	}
-
-	execCtx.Log("rss-source", "info", fmt.Sprintf("Retrieved %d items", len(items)))
-
-	return items, nil
-}
-
-func (n *RSSSourceNode) ValidateConfig(config map[string]interface{}) error {
-	url, ok := config["url"].(string)
-	if !ok || url == "" {
-		return fmt.Errorf("url is required")
-	}
-
-	return nil
-}
-
-func (n *RSSSourceNode) GetConfigSchema() *nodes.ConfigSchema {
-	return &nodes.ConfigSchema{
-		Fields: []nodes.ConfigField{
-			{
-				Name:        "url",
-				Label:       "Feed URL",
-				Type:        "url",
-				Required:    true,
-				Placeholder: "https://example.com/feed.xml",
-				HelpText:    "URL of the RSS or Atom feed",
-			},
-			{
-				Name:         "limit",
-				Label:        "Item Limit",
-				Type:         "number",
-				Required:     false,
-				DefaultValue: 50,
-				HelpText:     "Maximum number of items to fetch",
-			},
-		},
-	}
-}
-
-// parseDate tries multiple date formats
-func parseDate(s string) (time.Time, error) {
-	formats := []string{
-		time.RFC1123Z,
-		time.RFC1123,
-		time.RFC3339,
-		time.RFC822Z,
-		time.RFC822,
-		"Mon, 2 Jan 2006 15:04:05 MST",
-		"Mon, 2 Jan 2006 15:04:05 -0700",
-		"2006-01-02T15:04:05Z",
-		"2006-01-02T15:04:05-07:00",
-		"2006-01-02 15:04:05",
-		"2006-01-02",
-	}
-
-	for _, format := range formats {
-		if t, err := time.Parse(format, s); err == nil {
-			return t, nil
-		}
-	}
-
-	return time.Time{}, fmt.Errorf("unable to parse date: %s", s)
+	return items
 }
@@ -0,0 +1,262 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+// urlAttributes are resolved against the page URL when scraped.
+var urlAttributes = map[string]bool{
+	"href":     true,
+	"src":      true,
+	"data-src": true,
+	"poster":   true,
+	"action":   true,
+}
+
+type HTMLScraperNode struct{}
+
+// scrapeField is one "name = selector @attribute" line of a scraper's
+// fields. An empty selector means the item element itself; attr is "" for
+// its text or "html" for its inner HTML.
+type scrapeField struct {
+	name     string
+	selector goquery.Matcher
+	attr     string
+}
+
+func (n *HTMLScraperNode) Type() string        { return "html-scraper" }
+func (n *HTMLScraperNode) Label() string       { return "HTML Scraper" }
+func (n *HTMLScraperNode) Description() string { return "Turn elements of a web page into items" }
+func (n *HTMLScraperNode) Category() string    { return "source" }
+func (n *HTMLScraperNode) Inputs() int         { return 0 }
+func (n *HTMLScraperNode) Outputs() int        { return 1 }
+
+func (n *HTMLScraperNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	if err := n.ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	pageURL, _ := config["url"].(string)
+	itemSelector, _ := config["item_selector"].(string)
+	fields, err := parseScrapeFields(config)
+	if err != nil {
+		return nil, err
+	}
+
+	execCtx.Log("html-scraper", "info", fmt.Sprintf("Fetching %s", pageURL))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Pipes/1.0")
+
+	result, err := execCtx.Fetch(req, minRefresh(config))
+	if err != nil {
+		return nil, fmt.Errorf("fetch page: %w", err)
+	}
+	logCacheHit(execCtx, "html-scraper", result)
+
+	doc, err := nodes.ParseHTML(result.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	base := nodes.PageBase(doc, req.URL)
+
+	items := []interface{}{}
+	doc.Find(itemSelector).EachWithBreak(func(i int, el *goquery.Selection) bool {
+		if item := scrapeItem(el, fields, base); item != nil {
+			items = append(items, item)
+		}
+		limit, ok := nodes.ConfigNumber(config, "limit")
+		return !ok || limit <= 0 || len(items) < int(limit)
+	})
+
+	if len(items) == 0 {
+		execCtx.Log("html-scraper", "warn", fmt.Sprintf("No items matched %q", itemSelector))
+	}
+	execCtx.Log("html-scraper", "info", fmt.Sprintf("Retrieved %d items", len(items)))
+
+	return items, nil
+}
+
+// scrapeItem extracts the fields from one item element. Elements where no
+// field matched are skipped.
+func scrapeItem(el *goquery.Selection, fields []scrapeField, base *url.URL) map[string]interface{} {
+	item := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		target := el
+		if f.selector != nil {
+			target = el.FindMatcher(f.selector).First()
+		}
+		if target.Length() == 0 {
+			continue
+		}
+
+		var value string
+		switch f.attr {
+		case "":
+			value = strings.Join(strings.Fields(target.Text()), " ")
+		case "html":
+			html, err := target.Html()
+			if err != nil {
+				continue
+			}
+			value = strings.TrimSpace(html)
+		default:
+			v, ok := target.Attr(f.attr)
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(v)
+			if urlAttributes[f.attr] && value != "" {
+				if u, err := base.Parse(value); err == nil {
+					value = u.String()
+				}
+			}
+		}
+
+		item[f.name] = value
+	}
+
+	if len(item) == 0 {
+		return nil
+	}
+
+	// Fill in the fields other nodes expect of feed items
+	if link, ok := item["link"].(string); ok && item["guid"] == nil {
+		item["guid"] = link
+	}
+	if published, ok := item["published"].(string); ok && item["published_at"] == nil {
+		if t, err := nodes.ParseDate(published); err == nil {
+			item["published_at"] = t.Unix()
+		}
+	}
+
+	return item
+}
+
+// parseScrapeFields reads one "name = selector" per line, skipping blank
+// lines and lines starting with #. The selector can end with @attribute to
+// take an attribute instead of the text, or @html for the inner HTML.
+func parseScrapeFields(config map[string]interface{}) ([]scrapeField, error) {
+	text, _ := config["fields"].(string)
+
+	var fields []scrapeField
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, spec, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"name = selector\"", i+1)
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("line %d: invalid field name %q", i+1, name)
+		}
+
+		f := scrapeField{name: name}
+		spec = strings.TrimSpace(spec)
+		if at := strings.LastIndex(spec, "@"); at >= 0 && !strings.ContainsAny(spec[at:], " ]") {
+			f.attr = strings.ToLower(spec[at+1:])
+			spec = strings.TrimSpace(spec[:at])
+			if f.attr == "" {
+				return nil, fmt.Errorf("line %d: missing attribute name after @", i+1)
+			}
+		}
+
+		if spec != "" {
+			sel, err := cascadia.Compile(spec)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid selector %q: %w", i+1, spec, err)
+			}
+			f.selector = sel
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+func (n *HTMLScraperNode) ValidateConfig(config map[string]interface{}) error {
+	pageURL, _ := config["url"].(string)
+	if pageURL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if u, err := url.Parse(pageURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+
+	itemSelector, _ := config["item_selector"].(string)
+	if itemSelector == "" {
+		return fmt.Errorf("item selector is required")
+	}
+	if _, err := cascadia.Compile(itemSelector); err != nil {
+		return fmt.Errorf("invalid item selector: %w", err)
+	}
+
+	fields, err := parseScrapeFields(config)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("at least one field is required")
+	}
+
+	return nil
+}
+
+func (n *HTMLScraperNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:        "url",
+				Label:       "Page URL",
+				Type:        "url",
+				Required:    true,
+				Placeholder: "https://example.com/blog",
+				HelpText:    "URL of the page to scrape",
+			},
+			{
+				Name:        "item_selector",
+				Label:       "Item Selector",
+				Type:        "text",
+				Required:    true,
+				Placeholder: "article.post",
+				HelpText:    "CSS selector matching each item's element",
+			},
+			{
+				Name:        "fields",
+				Label:       "Fields",
+				Type:        "textarea",
+				Required:    true,
+				Placeholder: "title = h2\nlink = h2 a @href\nimage = img @src\ndescription = .summary @html\npublished = time @datetime",
+				HelpText:    "One per line: name = selector within the item. Takes the text, or an attribute with @name, or the inner HTML with @html. Leave the selector out to use the item element itself. Links are made absolute.",
+			},
+			{
+				Name:         "limit",
+				Label:        "Item Limit",
+				Type:         "number",
+				Required:     false,
+				DefaultValue: 50,
+				HelpText:     "Maximum number of items to fetch",
+			},
+			minRefreshField,
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+type TriggerPayloadNode struct{}
+
+func (n *TriggerPayloadNode) Type() string  { return "trigger-payload" }
+func (n *TriggerPayloadNode) Label() string { return "Trigger Payload" }
+func (n *TriggerPayloadNode) Description() string {
+	return "Use the JSON body posted to the pipe's webhook"
+}
+func (n *TriggerPayloadNode) Category() string { return "source" }
+func (n *TriggerPayloadNode) Inputs() int      { return 0 }
+func (n *TriggerPayloadNode) Outputs() int     { return 1 }
+
+func (n *TriggerPayloadNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	data := execCtx.TriggerPayload
+	if data == nil {
+		execCtx.Log("trigger-payload", "info", fmt.Sprintf("No payload for %s run", execCtx.TriggerType))
+		return []interface{}{}, nil
+	}
+
+	// Extract items from a path if specified
+	itemsPath, _ := config["items_path"].(string)
+	if itemsPath != "" {
+		data = nodes.ExtractPath(data, itemsPath)
+	}
+
+	var items []interface{}
+	switch v := data.(type) {
+	case nil:
+		items = []interface{}{}
+	case []interface{}:
+		items = v
+	default:
+		items = []interface{}{v}
+	}
+
+	execCtx.Log("trigger-payload", "info", fmt.Sprintf("Received %d items", len(items)))
+	return items, nil
+}
+
+func (n *TriggerPayloadNode) ValidateConfig(config map[string]interface{}) error {
+	return nil
+}
+
+func (n *TriggerPayloadNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:        "items_path",
+				Label:       "Items Path",
+				Type:        "text",
+				Required:    false,
+				Placeholder: "commits",
+				HelpText:    "Dot-notation path to the array of items in the payload. Leave empty to use the whole payload: each element of an array payload is an item, and any other payload is one item.",
+			},
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package nodes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kierank/pipes/store"
+)
+
+// stateChanges buffers the state written during a run. It is only saved by
+// CommitState once the whole run succeeds, so a failed run (e.g. a webhook
+// that couldn't be delivered) doesn't mark its items as seen.
+type stateChanges struct {
+	mu    sync.Mutex
+	state []store.NodeState
+	seen  []store.SeenItem
+}
+
+// GetState returns a value the current node saved in an earlier run.
+func (c *Context) GetState(key string) (string, bool, error) {
+	return c.DB.GetNodeState(c.PipeID, c.NodeID, key)
+}
+
+// SetState saves a value for the current node once the run succeeds.
+func (c *Context) SetState(key, value string) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	c.state.state = append(c.state.state, store.NodeState{NodeID: c.NodeID, Key: key, Value: value})
+}
+
+// SeenItems reports which of keys the current node marked as seen in
+// earlier runs and that haven't expired.
+func (c *Context) SeenItems(keys []string) (map[string]bool, error) {
+	return c.DB.GetSeenItems(c.PipeID, c.NodeID, keys)
+}
+
+// MarkSeen records keys as seen by the current node once the run succeeds.
+// They expire after ttl, or never if ttl is 0. Marking a key again extends
+// its expiry.
+func (c *Context) MarkSeen(keys []string, ttl time.Duration) {
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).Unix()
+	}
+
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	for _, key := range keys {
+		c.state.seen = append(c.state.seen, store.SeenItem{NodeID: c.NodeID, ItemKey: key, ExpiresAt: expiresAt})
+	}
+}
+
+// CommitState saves the state recorded during the run. The executor calls
+// it after every node has succeeded. Previews discard their state.
+func (c *Context) CommitState() error {
+	if c.Preview != nil {
+		return nil
+	}
+
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+
+	if len(c.state.state) == 0 && len(c.state.seen) == 0 {
+		return nil
+	}
+
+	if err := c.DB.CommitNodeState(c.PipeID, c.state.state, c.state.seen); err != nil {
+		return err
+	}
+
+	c.state.state = nil
+	c.state.seen = nil
+	return nil
+}
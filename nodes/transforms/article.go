@@ -0,0 +1,256 @@
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/kierank/pipes/nodes"
+	"github.com/kierank/pipes/store"
+)
+
+const (
+	defaultArticleCacheHours  = 168
+	defaultArticleConcurrency = 4
+	maxArticleConcurrency     = 8
+)
+
+type FullArticleNode struct{}
+
+// cachedArticle is what's kept in source_cache for each link, so the same
+// article isn't fetched and extracted again.
+type cachedArticle struct {
+	Title   string `json:"title,omitempty"`
+	Byline  string `json:"byline,omitempty"`
+	Image   string `json:"image,omitempty"`
+	Content string `json:"content"`
+	Length  int    `json:"length"`
+}
+
+func (n *FullArticleNode) Type() string        { return "full-article" }
+func (n *FullArticleNode) Label() string       { return "Full Article" }
+func (n *FullArticleNode) Description() string { return "Fetch the full article for each link" }
+func (n *FullArticleNode) Category() string    { return "transform" }
+func (n *FullArticleNode) Inputs() int         { return 1 }
+func (n *FullArticleNode) Outputs() int        { return 1 }
+
+func (n *FullArticleNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	if len(inputs) == 0 || len(inputs[0]) == 0 {
+		return []interface{}{}, nil
+	}
+
+	items := inputs[0]
+	linkField, _ := config["link_field"].(string)
+	if linkField == "" {
+		linkField = "link"
+	}
+
+	cacheHours := float64(defaultArticleCacheHours)
+	if h, ok := nodes.ConfigNumber(config, "cache_hours"); ok && h >= 0 {
+		cacheHours = h
+	}
+	cacheFor := time.Duration(cacheHours * float64(time.Hour))
+
+	concurrency := defaultArticleConcurrency
+	if c, ok := nodes.ConfigNumber(config, "concurrency"); ok && c >= 1 {
+		concurrency = min(int(c), maxArticleConcurrency)
+	}
+
+	result := make([]interface{}, len(items))
+	errs := make([]error, len(items))
+	extracted := make([]bool, len(items))
+	cached := make([]bool, len(items))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result[i] = items[i]
+
+				itemMap, ok := items[i].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				link, _ := getNestedValue(itemMap, linkField).(string)
+				if link == "" {
+					continue
+				}
+
+				var a *cachedArticle
+				a, cached[i], errs[i] = fetchArticle(ctx, link, cacheFor, execCtx)
+				if errs[i] == nil {
+					result[i] = withArticle(itemMap, a)
+					extracted[i] = true
+				}
+			}
+		}()
+	}
+
+	for i := range items {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	succeeded, fromCache, failed := 0, 0, 0
+	var firstErr error
+	for i := range items {
+		if errs[i] != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+		}
+		if extracted[i] {
+			succeeded++
+		}
+		if cached[i] {
+			fromCache++
+		}
+	}
+
+	if failed > 0 {
+		execCtx.Log("full-article", "warn", fmt.Sprintf("Couldn't extract %d articles, those items are unchanged (first: %v)", failed, firstErr))
+	}
+	execCtx.Log("full-article", "info", fmt.Sprintf("Extracted %d of %d articles (%d from cache)", succeeded, len(items), fromCache))
+
+	return result, nil
+}
+
+// fetchArticle returns the extracted article for a link, from the cache if
+// it was extracted within cacheFor.
+func fetchArticle(ctx context.Context, link string, cacheFor time.Duration, execCtx *nodes.Context) (*cachedArticle, bool, error) {
+	cacheKey := "article:" + link
+
+	if execCtx.DB != nil && cacheFor > 0 {
+		if entry, _ := execCtx.DB.GetSourceCache(execCtx.PipeID, execCtx.NodeID, cacheKey); entry != nil && (time.Now().Unix() < entry.ExpiresAt || execCtx.UseStaleCache()) {
+			var a cachedArticle
+			if err := json.Unmarshal([]byte(entry.Data), &a); err == nil {
+				return &a, true, nil
+			}
+		}
+	}
+
+	pageURL, err := url.Parse(link)
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") {
+		return nil, false, fmt.Errorf("%s: not an http or https URL", link)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Pipes/1.0")
+
+	body, err := execCtx.Send(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", link, err)
+	}
+
+	doc, err := nodes.ParseHTML(body)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", link, err)
+	}
+
+	extracted, err := extractArticle(doc, nodes.PageBase(doc, req.URL))
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", link, err)
+	}
+
+	a := &cachedArticle{
+		Title:   extracted.title,
+		Byline:  extracted.byline,
+		Image:   extracted.image,
+		Content: extracted.content,
+		Length:  extracted.length,
+	}
+
+	if execCtx.DB != nil && cacheFor > 0 && execCtx.Preview == nil {
+		if data, err := json.Marshal(a); err == nil {
+			execCtx.DB.SaveSourceCache(&store.SourceCache{
+				PipeID:    execCtx.PipeID,
+				NodeID:    execCtx.NodeID,
+				CacheKey:  cacheKey,
+				Data:      string(data),
+				ExpiresAt: time.Now().Add(cacheFor).Unix(),
+			})
+		}
+	}
+
+	return a, false, nil
+}
+
+// withArticle returns a copy of the item with the article as its content.
+// The title, author and image are only filled in where the item has none.
+func withArticle(item map[string]interface{}, a *cachedArticle) map[string]interface{} {
+	result := make(map[string]interface{}, len(item)+4)
+	for k, v := range item {
+		result[k] = v
+	}
+
+	result["content"] = a.Content
+	result["content_length"] = a.Length
+
+	fill := func(field, value string) {
+		if value == "" {
+			return
+		}
+		if existing, _ := result[field].(string); existing == "" {
+			result[field] = value
+		}
+	}
+	fill("title", a.Title)
+	fill("author", a.Byline)
+	fill("image", a.Image)
+
+	return result
+}
+
+func (n *FullArticleNode) ValidateConfig(config map[string]interface{}) error {
+	return nil
+}
+
+func (n *FullArticleNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:         "link_field",
+				Label:        "Link Field",
+				Type:         "text",
+				Required:     false,
+				DefaultValue: "link",
+				HelpText:     "Field holding the article URL (dot notation allowed)",
+			},
+			{
+				Name:         "cache_hours",
+				Label:        "Cache (hours)",
+				Type:         "number",
+				Required:     false,
+				DefaultValue: defaultArticleCacheHours,
+				HelpText:     "How long to reuse an extracted article before fetching it again, 0 to always fetch",
+			},
+			{
+				Name:         "concurrency",
+				Label:        "Concurrency",
+				Type:         "number",
+				Required:     false,
+				DefaultValue: defaultArticleConcurrency,
+				HelpText:     fmt.Sprintf("Articles fetched at once (at most %d)", maxArticleConcurrency),
+			},
+		},
+	}
+}
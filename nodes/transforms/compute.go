@@ -0,0 +1,142 @@
+package transforms
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kierank/pipes/expr"
+	"github.com/kierank/pipes/nodes"
+)
+
+var computeFieldName = regexp.MustCompile(`^[^\s.=]+(\.[^\s.=]+)*$`)
+
+type ComputeNode struct{}
+
+type computeField struct {
+	name string
+	prog *expr.Program
+}
+
+func (n *ComputeNode) Type() string        { return "compute" }
+func (n *ComputeNode) Label() string       { return "Compute" }
+func (n *ComputeNode) Description() string { return "Set fields from expressions" }
+func (n *ComputeNode) Category() string    { return "transform" }
+func (n *ComputeNode) Inputs() int         { return 1 }
+func (n *ComputeNode) Outputs() int        { return 1 }
+
+func (n *ComputeNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	if len(inputs) == 0 || len(inputs[0]) == 0 {
+		return []interface{}{}, nil
+	}
+
+	fields, err := parseComputeFields(config)
+	if err != nil {
+		return nil, err
+	}
+
+	items := inputs[0]
+	result := make([]interface{}, 0, len(items))
+	failed := 0
+	var firstErr error
+
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+
+		newItem := make(map[string]interface{}, len(itemMap)+len(fields))
+		for k, v := range itemMap {
+			newItem[k] = v
+		}
+
+		// Fields are set in order, so later expressions can use earlier
+		// results
+		itemFailed := false
+		for _, f := range fields {
+			v, err := f.prog.Eval(newItem)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", f.name, err)
+				}
+				itemFailed = true
+				v = nil
+			}
+			setNestedValue(newItem, f.name, v)
+		}
+		if itemFailed {
+			failed++
+		}
+
+		result = append(result, newItem)
+	}
+
+	if failed > 0 {
+		execCtx.Log("compute", "warn", fmt.Sprintf("%d of %d items had errors, those fields were set to null (first: %v)", failed, len(items), firstErr))
+	}
+	execCtx.Log("compute", "info", fmt.Sprintf("Computed %d fields for %d items", len(fields), len(result)))
+
+	return result, nil
+}
+
+// parseComputeFields reads one "field = expression" per line, skipping
+// blank lines and lines starting with #.
+func parseComputeFields(config map[string]interface{}) ([]computeField, error) {
+	text, _ := config["fields"].(string)
+
+	var fields []computeField
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, src, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"field = expression\"", i+1)
+		}
+
+		name = strings.TrimSpace(name)
+		if !computeFieldName.MatchString(name) {
+			return nil, fmt.Errorf("line %d: invalid field name %q", i+1, name)
+		}
+
+		prog, err := compileExpr(strings.TrimSpace(src))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		fields = append(fields, computeField{name: name, prog: prog})
+	}
+
+	return fields, nil
+}
+
+func (n *ComputeNode) ValidateConfig(config map[string]interface{}) error {
+	fields, err := parseComputeFields(config)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("at least one field is required")
+	}
+	return nil
+}
+
+func (n *ComputeNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:        "fields",
+				Label:       "Fields",
+				Type:        "textarea",
+				Required:    true,
+				Placeholder: "summary = substr(description, 0, 200)\nage_hours = round((now() - published_at) / 3600)\nauthor = author ?? \"unknown\"",
+				HelpText:    "One per line: field = expression. Expressions can use item fields (dot notation, [index]), + - * / %, comparisons, && || !, ?? for defaults, cond ? a : b, and functions like lower, replace, substr, len, contains, round, date, formatDate, now, days.",
+			},
+		},
+	}
+}
@@ -0,0 +1,387 @@
+package transforms
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+// condition is one "field operator value" test of a Filter's condition
+// list.
+type condition struct {
+	field    string
+	operator string
+	negate   bool
+	value    string
+
+	// Parsed forms of value, depending on the operator
+	values    []string
+	bound     float64
+	low, high float64
+	window    time.Duration
+	re        *regexp.Regexp
+}
+
+// conditionOperators maps each operator to whether it takes a value.
+// Operators can be negated with a "not-" prefix.
+var conditionOperators = map[string]bool{
+	"equals":      true,
+	"contains":    true,
+	"starts-with": true,
+	"ends-with":   true,
+	"regex":       true,
+	"in":          true,
+	">":           true,
+	">=":          true,
+	"<":           true,
+	"<=":          true,
+	"between":     true,
+	"within":      true,
+	"older-than":  true,
+	"exists":      false,
+	"empty":       false,
+}
+
+var conditionAliases = map[string]string{
+	"=":  "equals",
+	"==": "equals",
+	"!=": "not-equals",
+}
+
+// parseConditions reads one condition per line. Consecutive conditions must
+// all hold; a line containing just "or" starts another group, and an item
+// matches if any group does. Blank lines and lines starting with # are
+// skipped.
+func parseConditions(text string, caseSensitive bool) ([][]condition, error) {
+	var groups [][]condition
+	var group []condition
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.EqualFold(line, "or") {
+			if len(group) == 0 {
+				return nil, fmt.Errorf("line %d: \"or\" must separate groups of conditions", i+1)
+			}
+			groups = append(groups, group)
+			group = nil
+			continue
+		}
+
+		cond, err := parseCondition(line, caseSensitive)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		group = append(group, cond)
+	}
+
+	if len(group) == 0 && len(groups) > 0 {
+		return nil, fmt.Errorf("conditions can't end with \"or\"")
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func parseCondition(line string, caseSensitive bool) (condition, error) {
+	parts := strings.SplitN(line, " ", 3)
+	if len(parts) < 2 {
+		return condition{}, fmt.Errorf("expected \"field operator value\"")
+	}
+
+	cond := condition{field: parts[0], operator: strings.ToLower(parts[1])}
+	if len(parts) == 3 {
+		cond.value = unquote(strings.TrimSpace(parts[2]))
+	}
+
+	if alias, ok := conditionAliases[cond.operator]; ok {
+		cond.operator = alias
+	}
+	if base, ok := strings.CutPrefix(cond.operator, "not-"); ok {
+		cond.operator = base
+		cond.negate = true
+	}
+
+	needsValue, ok := conditionOperators[cond.operator]
+	if !ok {
+		return condition{}, fmt.Errorf("unknown operator %q", parts[1])
+	}
+	if needsValue && cond.value == "" && len(parts) < 3 {
+		return condition{}, fmt.Errorf("%s needs a value", parts[1])
+	}
+
+	var err error
+	switch cond.operator {
+	case "in":
+		for _, v := range strings.Split(cond.value, ",") {
+			cond.values = append(cond.values, strings.TrimSpace(v))
+		}
+
+	case ">", ">=", "<", "<=":
+		if cond.bound, err = parseBound(cond.value); err != nil {
+			return condition{}, err
+		}
+
+	case "between":
+		low, high, ok := strings.Cut(cond.value, " and ")
+		if !ok {
+			low, high, ok = strings.Cut(cond.value, ",")
+		}
+		if !ok {
+			return condition{}, fmt.Errorf("between needs two values, e.g. \"between 1 and 10\"")
+		}
+		if cond.low, err = parseBound(strings.TrimSpace(low)); err != nil {
+			return condition{}, err
+		}
+		if cond.high, err = parseBound(strings.TrimSpace(high)); err != nil {
+			return condition{}, err
+		}
+
+	case "within", "older-than":
+		if cond.window, err = parseWindow(cond.value); err != nil {
+			return condition{}, err
+		}
+
+	case "regex":
+		pattern := cond.value
+		if !caseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		if cond.re, err = regexp.Compile(pattern); err != nil {
+			return condition{}, fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+
+	return cond, nil
+}
+
+// unquote strips matching quotes around a value, so values can have
+// leading or trailing spaces or be empty.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// parseBound parses the value of a numeric comparison. Dates are accepted
+// too, and compare as Unix timestamps.
+func parseBound(s string) (float64, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	if t, err := nodes.ParseDate(s); err == nil {
+		return float64(t.Unix()), nil
+	}
+	return 0, fmt.Errorf("%q is not a number or date", s)
+}
+
+// parseWindow parses a duration such as "24h", "30m" or "7d". A plain
+// number is a number of hours.
+func parseWindow(s string) (time.Duration, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(f * float64(time.Hour)), nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if f, err := strconv.ParseFloat(days, 64); err == nil {
+			return time.Duration(f * 24 * float64(time.Hour)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration (e.g. 24h, 30m, 7d)", s)
+	}
+	return d, nil
+}
+
+func matchesConditions(item interface{}, groups [][]condition, caseSensitive bool) bool {
+	for _, group := range groups {
+		all := true
+		for i := range group {
+			if !group[i].matches(item, caseSensitive) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *condition) matches(item interface{}, caseSensitive bool) bool {
+	var fieldValue interface{}
+	if itemMap, ok := item.(map[string]interface{}); ok {
+		fieldValue = getNestedValue(itemMap, c.field)
+	}
+	return c.test(fieldValue, caseSensitive) != c.negate
+}
+
+func (c *condition) test(v interface{}, caseSensitive bool) bool {
+	fold := func(s string) string {
+		if caseSensitive {
+			return s
+		}
+		return strings.ToLower(s)
+	}
+
+	switch c.operator {
+	case "exists":
+		return v != nil
+
+	case "empty":
+		return isEmpty(v)
+
+	case "equals":
+		if n, ok := toNumber(v); ok {
+			if want, err := strconv.ParseFloat(c.value, 64); err == nil {
+				return n == want
+			}
+		}
+		return fold(valueString(v)) == fold(c.value)
+
+	case "contains":
+		// Arrays (e.g. categories) contain an element equal to the value
+		if elems, ok := toSlice(v); ok {
+			for _, elem := range elems {
+				if fold(valueString(elem)) == fold(c.value) {
+					return true
+				}
+			}
+			return false
+		}
+		return strings.Contains(fold(valueString(v)), fold(c.value))
+
+	case "starts-with":
+		return strings.HasPrefix(fold(valueString(v)), fold(c.value))
+
+	case "ends-with":
+		return strings.HasSuffix(fold(valueString(v)), fold(c.value))
+
+	case "regex":
+		return v != nil && c.re.MatchString(valueString(v))
+
+	case "in":
+		s := fold(valueString(v))
+		for _, want := range c.values {
+			if s == fold(want) {
+				return true
+			}
+		}
+		return false
+
+	case ">", ">=", "<", "<=":
+		n, ok := toComparable(v)
+		if !ok {
+			return false
+		}
+		switch c.operator {
+		case ">":
+			return n > c.bound
+		case ">=":
+			return n >= c.bound
+		case "<":
+			return n < c.bound
+		default:
+			return n <= c.bound
+		}
+
+	case "between":
+		n, ok := toComparable(v)
+		return ok && n >= c.low && n <= c.high
+
+	case "within", "older-than":
+		n, ok := toComparable(v)
+		if !ok {
+			return false
+		}
+		age := time.Since(time.Unix(int64(n), 0))
+		if c.operator == "within" {
+			return age <= c.window
+		}
+		return age > c.window
+	}
+
+	return false
+}
+
+func isEmpty(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	}
+	if elems, ok := toSlice(v); ok {
+		return len(elems) == 0
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map {
+		return rv.Len() == 0
+	}
+	return false
+}
+
+// toNumber converts numeric fields, and strings holding a number.
+func toNumber(v interface{}) (float64, bool) {
+	if f, ok := toFloat(v); ok {
+		return f, true
+	}
+	if s, ok := v.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toComparable converts a field to a number for ordering. Dates, either as
+// strings or as Unix timestamps, become timestamps.
+func toComparable(v interface{}) (float64, bool) {
+	if n, ok := toNumber(v); ok {
+		return n, true
+	}
+	if s, ok := v.(string); ok {
+		if t, err := nodes.ParseDate(strings.TrimSpace(s)); err == nil {
+			return float64(t.Unix()), true
+		}
+	}
+	return 0, false
+}
+
+// toSlice returns the elements of an array field of any element type.
+func toSlice(v interface{}) ([]interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	elems := make([]interface{}, rv.Len())
+	for i := range elems {
+		elems[i] = rv.Index(i).Interface()
+	}
+	return elems, true
+}
+
+// valueString formats a field for string comparisons; missing fields are
+// empty.
+func valueString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
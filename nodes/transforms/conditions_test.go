@@ -0,0 +1,203 @@
+package transforms
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConditionsErrors(t *testing.T) {
+	tests := []struct {
+		text    string
+		wantErr string
+	}{
+		{text: "title", wantErr: `line 1: expected "field operator value"`},
+		{text: "title sounds-like go", wantErr: `line 1: unknown operator "sounds-like"`},
+		{text: "title not-sounds-like go", wantErr: `unknown operator "not-sounds-like"`},
+		{text: "title contains", wantErr: "line 1: contains needs a value"},
+		{text: "title exists\nscore >", wantErr: "line 2: > needs a value"},
+		{text: "score > lots", wantErr: `"lots" is not a number or date`},
+		{text: "score between 1", wantErr: "between needs two values"},
+		{text: "score between 1 and many", wantErr: `"many" is not a number or date`},
+		{text: "published within soon", wantErr: `"soon" is not a duration`},
+		{text: "title regex (", wantErr: "invalid regex"},
+		{text: "or\ntitle exists", wantErr: `line 1: "or" must separate groups`},
+		{text: "title exists\nor\n\nor\nlink exists", wantErr: `line 4: "or" must separate groups`},
+		{text: "title exists\nor", wantErr: `can't end with "or"`},
+	}
+
+	for _, tt := range tests {
+		_, err := parseConditions(tt.text, false)
+		if err == nil {
+			t.Errorf("parseConditions(%q) succeeded, want error %q", tt.text, tt.wantErr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseConditions(%q) error = %q, want %q", tt.text, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseConditions(t *testing.T) {
+	groups, err := parseConditions(`
+		# comments and blank lines are skipped
+
+		title == "  padded  "
+		score not-between 1 and 10
+		OR
+		tags in a, b ,c
+	`, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || len(groups[0]) != 2 || len(groups[1]) != 1 {
+		t.Fatalf("got groups %+v", groups)
+	}
+
+	title, score, tags := groups[0][0], groups[0][1], groups[1][0]
+	if title.operator != "equals" || title.value != "  padded  " {
+		t.Errorf("title condition = %+v", title)
+	}
+	if score.operator != "between" || !score.negate || score.low != 1 || score.high != 10 {
+		t.Errorf("score condition = %+v", score)
+	}
+	if want := []string{"a", "b", "c"}; strings.Join(tags.values, "|") != strings.Join(want, "|") {
+		t.Errorf("in values = %q, want %q", tags.values, want)
+	}
+
+	if groups, err := parseConditions("  \n# nothing\n", false); err != nil || groups != nil {
+		t.Errorf("empty conditions = %v, %v; want nil, nil", groups, err)
+	}
+}
+
+func TestConditionMatches(t *testing.T) {
+	now := time.Now()
+	item := map[string]interface{}{
+		"title":      "Go 1.24 Released",
+		"score":      float64(42),
+		"count":      "7",
+		"tags":       []interface{}{"golang", "Release"},
+		"ids":        []int{1, 2, 3},
+		"empty":      "  ",
+		"none":       []interface{}{},
+		"meta":       map[string]interface{}{},
+		"author":     map[string]interface{}{"name": "Ann"},
+		"published":  now.Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+		"updated_at": float64(now.Add(-10 * 24 * time.Hour).Unix()),
+		"date":       "2026-01-15",
+		"timestamp":  float64(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Unix()),
+	}
+
+	tests := []struct {
+		cond          string
+		caseSensitive bool
+		want          bool
+	}{
+		// Strings fold case unless asked not to
+		{cond: `title contains "go 1"`, want: true},
+		{cond: `title contains "go 1"`, caseSensitive: true, want: false},
+		{cond: `title starts-with go`, want: true},
+		{cond: `title ends-with released`, want: true},
+		{cond: `title not-contains rust`, want: true},
+		{cond: `author.name equals ann`, want: true},
+		{cond: `author.name equals ann`, caseSensitive: true, want: false},
+		{cond: `author.missing equals ""`, want: true},
+
+		// Numeric equals compares numbers, including numeric strings
+		{cond: `score equals 42`, want: true},
+		{cond: `score equals 42.0`, want: true},
+		{cond: `score = 42`, want: true},
+		{cond: `score != 41`, want: true},
+		{cond: `count == 7.00`, want: true},
+		{cond: `title equals 42`, want: false},
+
+		// Arrays contain an equal element, rather than a substring
+		{cond: `tags contains golang`, want: true},
+		{cond: `tags contains release`, want: true},
+		{cond: `tags contains release`, caseSensitive: true, want: false},
+		{cond: `tags contains go`, want: false},
+		{cond: `ids contains 2`, want: true},
+		{cond: `ids not-contains 4`, want: true},
+
+		{cond: `title in "go, rust"`, want: false},
+		{cond: `author.name in bob, ann`, want: true},
+
+		{cond: `title regex ^go \d`, want: true},
+		{cond: `title regex ^go \d`, caseSensitive: true, want: false},
+		{cond: `missing regex .*`, want: false},
+
+		{cond: `score > 41`, want: true},
+		{cond: `score >= 42`, want: true},
+		{cond: `score < 42`, want: false},
+		{cond: `score <= 42`, want: true},
+		{cond: `count > 6`, want: true},
+		{cond: `title > 1`, want: false},
+		{cond: `score between 40 and 50`, want: true},
+		{cond: `score between 40,41`, want: false},
+		{cond: `score not-between 1 and 10`, want: true},
+
+		// Date bounds compare dates as timestamps
+		{cond: `date > 2026-01-01`, want: true},
+		{cond: `date < 2026-01-15`, want: false},
+		{cond: `date <= 2026-01-15`, want: true},
+		{cond: `date between 2026-01-01 and 2026-01-31`, want: true},
+		{cond: `date between 2026-02-01 and 2026-02-28`, want: false},
+		{cond: `timestamp < 2026-01-02`, want: true},
+		{cond: `timestamp > 2026-01-01`, want: false},
+		{cond: `timestamp >= 2026-01-01T00:00:00Z`, want: true},
+		{cond: `published within 3h`, want: true},
+		{cond: `published within 1h`, want: false},
+		{cond: `published within 1`, want: false},
+		{cond: `updated_at older-than 7d`, want: true},
+		{cond: `updated_at within 30d`, want: true},
+		{cond: `title within 7d`, want: false},
+
+		{cond: `title exists`, want: true},
+		{cond: `missing exists`, want: false},
+		{cond: `missing not-exists`, want: true},
+		{cond: `empty empty`, want: true},
+		{cond: `none empty`, want: true},
+		{cond: `meta empty`, want: true},
+		{cond: `missing empty`, want: true},
+		{cond: `score empty`, want: false},
+		{cond: `tags not-empty`, want: true},
+	}
+
+	for _, tt := range tests {
+		groups, err := parseConditions(tt.cond, tt.caseSensitive)
+		if err != nil {
+			t.Errorf("parseConditions(%q): %v", tt.cond, err)
+			continue
+		}
+		if got := matchesConditions(item, groups, tt.caseSensitive); got != tt.want {
+			t.Errorf("%q (case sensitive %v) = %v, want %v", tt.cond, tt.caseSensitive, got, tt.want)
+		}
+	}
+}
+
+func TestConditionGroups(t *testing.T) {
+	groups, err := parseConditions("score > 10\ntitle contains go\nor\ntags contains featured", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		item map[string]interface{}
+		want bool
+	}{
+		{item: map[string]interface{}{"score": 20, "title": "Go"}, want: true},
+		{item: map[string]interface{}{"score": 5, "title": "Go"}, want: false},
+		{item: map[string]interface{}{"score": 5, "tags": []string{"featured"}}, want: true},
+		{item: map[string]interface{}{}, want: false},
+	}
+	for _, tt := range tests {
+		if got := matchesConditions(tt.item, groups, false); got != tt.want {
+			t.Errorf("matchesConditions(%v) = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+
+	// Items that aren't objects have no fields
+	if matchesConditions("score", groups, false) {
+		t.Error("a string item matched")
+	}
+}
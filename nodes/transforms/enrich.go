@@ -0,0 +1,388 @@
+package transforms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+const (
+	defaultEnrichConcurrency = 4
+	maxEnrichConcurrency     = 16
+	maxEnrichRate            = 1000
+	enrichErrorField         = "enrich_error"
+)
+
+var templateField = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+type HTTPEnrichNode struct{}
+
+func (n *HTTPEnrichNode) Type() string        { return "http-enrich" }
+func (n *HTTPEnrichNode) Label() string       { return "HTTP Enrich" }
+func (n *HTTPEnrichNode) Description() string { return "Fetch JSON for each item and add it" }
+func (n *HTTPEnrichNode) Category() string    { return "transform" }
+func (n *HTTPEnrichNode) Inputs() int         { return 1 }
+func (n *HTTPEnrichNode) Outputs() int        { return 1 }
+
+func (n *HTTPEnrichNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	if len(inputs) == 0 || len(inputs[0]) == 0 {
+		return []interface{}{}, nil
+	}
+
+	if err := n.ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	items := inputs[0]
+	onError, _ := config["on_error"].(string)
+	if onError == "" {
+		onError = "tag"
+	}
+
+	concurrency := defaultEnrichConcurrency
+	if c, ok := nodes.ConfigNumber(config, "concurrency"); ok && c >= 1 {
+		concurrency = min(int(c), maxEnrichConcurrency)
+	}
+
+	// Requests are spaced evenly to stay under the rate limit
+	var limiter *time.Ticker
+	if rate, ok := nodes.ConfigNumber(config, "rate_limit"); ok && rate > 0 {
+		limiter = time.NewTicker(rateInterval(rate))
+		defer limiter.Stop()
+	}
+
+	// In fail mode the first failure stops the remaining requests
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failOnce sync.Once
+	var failErr error
+
+	results := make([]interface{}, len(items))
+	errs := make([]error, len(items))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if limiter != nil {
+					select {
+					case <-limiter.C:
+					case <-reqCtx.Done():
+						errs[i] = reqCtx.Err()
+						continue
+					}
+				}
+
+				results[i], errs[i] = enrichItem(reqCtx, config, items[i], execCtx)
+				if errs[i] != nil && onError == "fail" {
+					failOnce.Do(func() {
+						failErr = fmt.Errorf("item %d: %w", i+1, errs[i])
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for i := range items {
+		select {
+		case jobs <- i:
+		case <-reqCtx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if failErr != nil {
+		return nil, failErr
+	}
+
+	result := make([]interface{}, 0, len(items))
+	failed := 0
+	var firstErr error
+
+	for i, item := range items {
+		if errs[i] == nil {
+			result = append(result, results[i])
+			continue
+		}
+
+		failed++
+		if firstErr == nil {
+			firstErr = fmt.Errorf("item %d: %w", i+1, errs[i])
+		}
+
+		if onError == "tag" {
+			if itemMap, ok := item.(map[string]interface{}); ok {
+				tagged := make(map[string]interface{}, len(itemMap)+1)
+				for k, v := range itemMap {
+					tagged[k] = v
+				}
+				tagged[enrichErrorField] = errs[i].Error()
+				item = tagged
+			}
+			result = append(result, item)
+		}
+	}
+
+	if failed > 0 {
+		action := "dropped"
+		if onError == "tag" {
+			action = "tagged with " + enrichErrorField
+		}
+		execCtx.Log("http-enrich", "warn", fmt.Sprintf("%d of %d requests failed and were %s (first: %v)", failed, len(items), action, firstErr))
+	}
+	execCtx.Log("http-enrich", "info", fmt.Sprintf("Enriched %d of %d items", len(items)-failed, len(items)))
+
+	return result, nil
+}
+
+// enrichItem fetches the response for one item and returns a copy of the
+// item with the response added.
+func enrichItem(ctx context.Context, config map[string]interface{}, item interface{}, execCtx *nodes.Context) (interface{}, error) {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("item is not an object")
+	}
+
+	urlTemplate, _ := config["url"].(string)
+	reqURL := renderTemplate(urlTemplate, itemMap, func(v interface{}) string {
+		return strings.ReplaceAll(url.QueryEscape(valueString(v)), "+", "%20")
+	})
+
+	method, _ := config["method"].(string)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if bodyTemplate, _ := config["body"].(string); bodyTemplate != "" && method != http.MethodGet {
+		body = strings.NewReader(renderTemplate(bodyTemplate, itemMap, func(v interface{}) string {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "null"
+			}
+			return string(b)
+		}))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Add custom headers
+	if headers, ok := config["headers"].(string); ok && headers != "" {
+		for _, line := range strings.Split(headers, "\n") {
+			if parts := strings.SplitN(strings.TrimSpace(line), ":", 2); len(parts) == 2 {
+				req.Header.Set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+			}
+		}
+	}
+
+	req.Header.Set("User-Agent", "Pipes/1.0")
+
+	// GET responses go through the source cache, so unchanged items aren't
+	// refetched on every run
+	var respBody []byte
+	if method == http.MethodGet {
+		minutes, _ := nodes.ConfigNumber(config, "min_refresh")
+		result, err := execCtx.Fetch(req, time.Duration(minutes*float64(time.Minute)))
+		if err != nil {
+			return nil, err
+		}
+		respBody = result.Body
+	} else {
+		if respBody, err = execCtx.Send(req); err != nil {
+			return nil, err
+		}
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(respBody, &data); err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+
+	if path, _ := config["response_path"].(string); path != "" {
+		data = nodes.ExtractPath(data, path)
+	}
+
+	enriched := make(map[string]interface{}, len(itemMap))
+	for k, v := range itemMap {
+		enriched[k] = v
+	}
+
+	// Without a target field, an object response's fields are merged in
+	target, _ := config["target"].(string)
+	if obj, ok := data.(map[string]interface{}); ok && target == "" {
+		for k, v := range obj {
+			enriched[k] = v
+		}
+	} else {
+		if target == "" {
+			target = "response"
+		}
+		setNestedValue(enriched, target, data)
+	}
+
+	return enriched, nil
+}
+
+// renderTemplate replaces {{field}} placeholders with the item's values,
+// formatted by format. Missing fields are passed to format as nil.
+func renderTemplate(tmpl string, item map[string]interface{}, format func(interface{}) string) string {
+	return templateField.ReplaceAllStringFunc(tmpl, func(match string) string {
+		path := templateField.FindStringSubmatch(match)[1]
+		return format(getNestedValue(item, path))
+	})
+}
+
+func (n *HTTPEnrichNode) ValidateConfig(config map[string]interface{}) error {
+	urlTemplate, _ := config["url"].(string)
+	if urlTemplate == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	// Check the URL with placeholders filled in
+	u, err := url.Parse(templateField.ReplaceAllString(urlTemplate, "x"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+
+	switch method, _ := config["method"].(string); method {
+	case "", http.MethodGet, http.MethodPost, http.MethodPut:
+	default:
+		return fmt.Errorf("unsupported method %q", method)
+	}
+
+	switch onError, _ := config["on_error"].(string); onError {
+	case "", "drop", "tag", "fail":
+	default:
+		return fmt.Errorf("unknown error handling %q", onError)
+	}
+
+	if rate, ok := nodes.ConfigNumber(config, "rate_limit"); ok && !(rate >= 0 && rate <= maxEnrichRate) {
+		return fmt.Errorf("rate_limit must be between 0 and %d requests per second", maxEnrichRate)
+	}
+
+	return nil
+}
+
+// rateInterval is the time between requests at a rate per second. Tickers
+// need a positive interval, which very high rates would round down from.
+func rateInterval(rate float64) time.Duration {
+	return max(time.Duration(float64(time.Second)/rate), time.Nanosecond)
+}
+
+func (n *HTTPEnrichNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:        "url",
+				Label:       "URL",
+				Type:        "text",
+				Required:    true,
+				Placeholder: "https://api.example.com/lookup?url={{link}}",
+				HelpText:    "Request URL. {{field}} is replaced with the item's field, URL-escaped (dot notation allowed).",
+			},
+			{
+				Name:     "method",
+				Label:    "Method",
+				Type:     "select",
+				Required: false,
+				Options: []nodes.FieldOption{
+					{Value: "GET", Label: "GET"},
+					{Value: "POST", Label: "POST"},
+					{Value: "PUT", Label: "PUT"},
+				},
+			},
+			{
+				Name:        "body",
+				Label:       "Body",
+				Type:        "textarea",
+				Required:    false,
+				Placeholder: "{\"url\": {{link}}, \"title\": {{title}}}",
+				HelpText:    "JSON body for POST and PUT. {{field}} is replaced with the field's JSON value, quotes included.",
+			},
+			{
+				Name:        "headers",
+				Label:       "Headers",
+				Type:        "textarea",
+				Required:    false,
+				Placeholder: "Authorization: Bearer token",
+				HelpText:    "Custom headers, one per line as Header: Value",
+			},
+			{
+				Name:        "response_path",
+				Label:       "Response Path",
+				Type:        "text",
+				Required:    false,
+				Placeholder: "data.0",
+				HelpText:    "Dot-notation path to the part of the response to add",
+			},
+			{
+				Name:        "target",
+				Label:       "Target Field",
+				Type:        "text",
+				Required:    false,
+				Placeholder: "details",
+				HelpText:    "Field to store the response in. Leave empty to merge an object response into the item.",
+			},
+			{
+				Name:         "concurrency",
+				Label:        "Concurrency",
+				Type:         "number",
+				Required:     false,
+				DefaultValue: defaultEnrichConcurrency,
+				HelpText:     fmt.Sprintf("Requests in flight at once (at most %d)", maxEnrichConcurrency),
+			},
+			{
+				Name:        "rate_limit",
+				Label:       "Rate Limit (per second)",
+				Type:        "number",
+				Required:    false,
+				Placeholder: "0",
+				HelpText:    fmt.Sprintf("Maximum requests per second (at most %d), 0 for no limit", maxEnrichRate),
+			},
+			{
+				Name:     "on_error",
+				Label:    "On Error",
+				Type:     "select",
+				Required: false,
+				Options: []nodes.FieldOption{
+					{Value: "tag", Label: "Keep item, set " + enrichErrorField},
+					{Value: "drop", Label: "Drop item"},
+					{Value: "fail", Label: "Fail the run"},
+				},
+				HelpText: "What to do with items whose request fails",
+			},
+			{
+				Name:        "min_refresh",
+				Label:       "Minimum Refresh (minutes)",
+				Type:        "number",
+				Required:    false,
+				Placeholder: "0",
+				HelpText:    "Reuse cached GET responses for at least this long, even if upstream allows less",
+			},
+		},
+	}
+}
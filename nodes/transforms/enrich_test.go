@@ -0,0 +1,66 @@
+package transforms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+func TestEnrichRateLimit(t *testing.T) {
+	node := &HTTPEnrichNode{}
+
+	for _, rate := range []interface{}{float64(0), float64(0.5), float64(1000), "10", 5} {
+		if err := node.ValidateConfig(map[string]interface{}{"url": "https://example.com/{{id}}", "rate_limit": rate}); err != nil {
+			t.Errorf("rate_limit %v: %v", rate, err)
+		}
+	}
+
+	// Rates too high for a ticker used to panic the run
+	for _, rate := range []interface{}{float64(1e9), float64(1e300), float64(-1), "NaN", "+Inf", float64(1000.5)} {
+		config := map[string]interface{}{"url": "https://example.com/{{id}}", "rate_limit": rate}
+		if err := node.ValidateConfig(config); err == nil {
+			t.Errorf("rate_limit %v is valid, want error", rate)
+		}
+		if _, err := node.Execute(context.Background(), config, [][]interface{}{{map[string]interface{}{"id": "1"}}}, &nodes.Context{Preview: &nodes.Preview{}}); err == nil {
+			t.Errorf("rate_limit %v: Execute succeeded, want error", rate)
+		}
+	}
+
+	for _, rate := range []float64{1, 1000, 1e9, 1e300} {
+		if d := rateInterval(rate); d <= 0 || d > time.Second {
+			t.Errorf("rateInterval(%v) = %v", rate, d)
+		}
+	}
+}
+
+func TestEnrichAtMaxRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "` + r.URL.Path[1:] + `"}`))
+	}))
+	defer server.Close()
+
+	items := []interface{}{
+		map[string]interface{}{"id": "a"},
+		map[string]interface{}{"id": "b"},
+		map[string]interface{}{"id": "c"},
+	}
+	config := map[string]interface{}{"url": server.URL + "/{{id}}", "target": "details", "rate_limit": float64(maxEnrichRate)}
+
+	result, err := (&HTTPEnrichNode{}).Execute(context.Background(), config, [][]interface{}{items}, &nodes.Context{Preview: &nodes.Preview{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != len(items) {
+		t.Fatalf("got %d items, want %d", len(result), len(items))
+	}
+	for i, item := range result {
+		details, _ := item.(map[string]interface{})["details"].(map[string]interface{})
+		if want := items[i].(map[string]interface{})["id"]; details["id"] != want {
+			t.Errorf("item %d = %v, want details for %v", i, item, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package transforms
+
+import (
+	"sync"
+
+	"github.com/kierank/pipes/expr"
+)
+
+// maxCachedExprs bounds the expression cache. The editor validates configs
+// as they're typed, so old entries are dropped rather than kept forever.
+const maxCachedExprs = 1024
+
+var (
+	exprCacheMu sync.Mutex
+	exprCache   = make(map[string]*expr.Program)
+)
+
+// compileExpr compiles an expression, reusing the program if the same
+// source was compiled before. ValidateConfig compiles a node's expressions
+// so that syntax errors surface early; Execute then gets them from the
+// cache.
+func compileExpr(src string) (*expr.Program, error) {
+	exprCacheMu.Lock()
+	prog, ok := exprCache[src]
+	exprCacheMu.Unlock()
+	if ok {
+		return prog, nil
+	}
+
+	prog, err := expr.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+
+	exprCacheMu.Lock()
+	if len(exprCache) >= maxCachedExprs {
+		clear(exprCache)
+	}
+	exprCache[src] = prog
+	exprCacheMu.Unlock()
+
+	return prog, nil
+}
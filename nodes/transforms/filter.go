@@ -3,9 +3,9 @@ package transforms
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"strings"
 
+	"github.com/kierank/pipes/expr"
 	"github.com/kierank/pipes/nodes"
 )
 
@@ -18,58 +18,118 @@ func (n *FilterNode) Category() string    { return "transform" }
 func (n *FilterNode) Inputs() int         { return 1 }
 func (n *FilterNode) Outputs() int        { return 1 }
 
+// OutputPorts splits items into those that pass the filter and those that
+// don't; the default port carries the matches.
+func (n *FilterNode) OutputPorts(config map[string]interface{}) []string {
+	return []string{"matched", "unmatched"}
+}
+
 func (n *FilterNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	ports, err := n.ExecutePorts(ctx, config, inputs, execCtx)
+	if err != nil {
+		return nil, err
+	}
+	return ports["matched"], nil
+}
+
+func (n *FilterNode) ExecutePorts(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) (map[string][]interface{}, error) {
 	if len(inputs) == 0 {
-		return []interface{}{}, nil
+		return map[string][]interface{}{"matched": {}, "unmatched": {}}, nil
 	}
 
 	items := inputs[0]
 
-	field, _ := config["field"].(string)
-	operator, _ := config["operator"].(string)
-	value, _ := config["value"].(string)
+	match, err := filterPredicate(config)
+	if err != nil {
+		return nil, err
+	}
 
-	if field == "" || operator == "" {
-		return items, nil
+	if match == nil {
+		return map[string][]interface{}{"matched": items, "unmatched": {}}, nil
 	}
 
-	var filtered []interface{}
+	// In drop mode the items that meet the conditions are the ones removed
+	keep := config["action"] != "drop"
+
+	filtered := []interface{}{}
+	rejected := []interface{}{}
+	failed := 0
+	var firstErr error
+
 	for _, item := range items {
-		if matchesFilter(item, field, operator, value) {
+		ok, err := match(item)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+
+		if ok == keep {
 			filtered = append(filtered, item)
+		} else {
+			rejected = append(rejected, item)
 		}
 	}
 
+	if failed > 0 {
+		execCtx.Log("filter", "warn", fmt.Sprintf("Expression failed on %d of %d items, treated as not matching (first: %v)", failed, len(items), firstErr))
+	}
 	execCtx.Log("filter", "info", fmt.Sprintf("Filtered %d -> %d items", len(items), len(filtered)))
 
-	return filtered, nil
+	return map[string][]interface{}{"matched": filtered, "unmatched": rejected}, nil
+}
+
+// filterPredicate returns the test for items meeting the filter's
+// expression or condition list, depending on its mode. It returns nil if
+// there is nothing to test.
+func filterPredicate(config map[string]interface{}) (func(item interface{}) (bool, error), error) {
+	if mode, _ := config["mode"].(string); mode == "expression" {
+		prog, err := filterExpression(config)
+		if err != nil {
+			return nil, err
+		}
+		return prog.EvalBool, nil
+	}
+
+	text, _ := config["conditions"].(string)
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	caseSensitive := config["case"] == "sensitive"
+	groups, err := parseConditions(text, caseSensitive)
+	if err != nil {
+		return nil, err
+	}
+	return func(item interface{}) (bool, error) {
+		return matchesConditions(item, groups, caseSensitive), nil
+	}, nil
 }
 
-func matchesFilter(item interface{}, field, operator, value string) bool {
-	itemMap, ok := item.(map[string]interface{})
-	if !ok {
-		return false
+func filterExpression(config map[string]interface{}) (*expr.Program, error) {
+	src, _ := config["expression"].(string)
+	if strings.TrimSpace(src) == "" {
+		return nil, fmt.Errorf("expression is required in expression mode")
 	}
 
-	fieldValue := getNestedValue(itemMap, field)
-	fieldStr := fmt.Sprintf("%v", fieldValue)
-
-	switch operator {
-	case "contains":
-		return strings.Contains(strings.ToLower(fieldStr), strings.ToLower(value))
-	case "equals":
-		return fieldStr == value
-	case "not-equals":
-		return fieldStr != value
-	case "regex":
-		matched, _ := regexp.MatchString(value, fieldStr)
-		return matched
-	default:
-		return true
+	prog, err := compileExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
 	}
+	return prog, nil
 }
 
 func (n *FilterNode) ValidateConfig(config map[string]interface{}) error {
+	if mode, _ := config["mode"].(string); mode == "expression" {
+		_, err := filterExpression(config)
+		return err
+	}
+
+	if text, _ := config["conditions"].(string); strings.TrimSpace(text) != "" {
+		_, err := parseConditions(text, config["case"] == "sensitive")
+		return err
+	}
 	return nil
 }
 
@@ -77,31 +137,53 @@ func (n *FilterNode) GetConfigSchema() *nodes.ConfigSchema {
 	return &nodes.ConfigSchema{
 		Fields: []nodes.ConfigField{
 			{
-				Name:        "field",
-				Label:       "Field Path",
-				Type:        "text",
-				Required:    true,
-				Placeholder: "title",
-				HelpText:    "Field to filter on (use dot notation for nested: author.name)",
+				Name:     "mode",
+				Label:    "Mode",
+				Type:     "select",
+				Required: false,
+				Options: []nodes.FieldOption{
+					{Value: "condition", Label: "Conditions"},
+					{Value: "expression", Label: "Expression"},
+				},
+				HelpText: "Match on field conditions, or on a boolean expression",
+			},
+			{
+				Name:        "conditions",
+				Label:       "Conditions",
+				Type:        "textarea",
+				Required:    false,
+				Placeholder: "published_at within 24h\ncategories contains golang\nor\nscore between 10 and 100",
+				HelpText:    "One \"field operator value\" per line. Lines must all match; a line with just \"or\" starts another group, and an item matches if any group does. Operators: equals (=), contains (text or array element), starts-with, ends-with, regex, in (comma-separated list), > >= < <= and between (numbers or dates), within / older-than (24h, 30m, 7d), exists, empty. Prefix any operator with not- to negate it.",
 			},
 			{
-				Name:     "operator",
-				Label:    "Operator",
+				Name:     "case",
+				Label:    "Case",
 				Type:     "select",
-				Required: true,
+				Required: false,
 				Options: []nodes.FieldOption{
-					{Value: "contains", Label: "Contains"},
-					{Value: "equals", Label: "Equals"},
-					{Value: "not-equals", Label: "Not Equals"},
-					{Value: "regex", Label: "Regex Match"},
+					{Value: "insensitive", Label: "Ignore case"},
+					{Value: "sensitive", Label: "Match case"},
 				},
+				HelpText: "Whether text comparisons in the condition list are case-sensitive",
+			},
+			{
+				Name:        "expression",
+				Label:       "Expression",
+				Type:        "textarea",
+				Required:    false,
+				Placeholder: "published_at > now() - days(1) && !contains(lower(title), \"sponsored\")",
+				HelpText:    "In expression mode, items for which this is true are matched. See the Compute node for the syntax.",
 			},
 			{
-				Name:        "value",
-				Label:       "Value",
-				Type:        "text",
-				Required:    true,
-				Placeholder: "search term",
+				Name:     "action",
+				Label:    "Action",
+				Type:     "select",
+				Required: false,
+				Options: []nodes.FieldOption{
+					{Value: "keep", Label: "Keep matching items"},
+					{Value: "drop", Label: "Drop matching items"},
+				},
+				HelpText: "The items kept go to the matched output, the rest to unmatched",
 			},
 		},
 	}
@@ -0,0 +1,144 @@
+package transforms_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kierank/pipes/engine"
+	"github.com/kierank/pipes/nodes"
+	"github.com/kierank/pipes/nodes/transforms"
+)
+
+func runFilter(t *testing.T, config map[string]interface{}, items []interface{}) (map[string][]interface{}, *nodes.Preview) {
+	t.Helper()
+	preview := &nodes.Preview{}
+	ports, err := (&transforms.FilterNode{}).ExecutePorts(context.Background(), config, [][]interface{}{items}, &nodes.Context{Preview: preview})
+	if err != nil {
+		t.Fatalf("filter: %v", err)
+	}
+	return ports, preview
+}
+
+func titles(items []interface{}) []string {
+	names := []string{}
+	for _, item := range items {
+		names = append(names, item.(map[string]interface{})["title"].(string))
+	}
+	return names
+}
+
+func filterItems() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"title": "Go 1.24", "score": float64(10), "divisor": float64(2)},
+		map[string]interface{}{"title": "Rust 2.0", "score": float64(3), "divisor": float64(1)},
+		map[string]interface{}{"title": "go fmt", "score": float64(8), "divisor": float64(0)},
+	}
+}
+
+// Configs saved before the condition list had a single field, operator and
+// value, which are upgraded when the config is loaded.
+func TestFilterLegacyCondition(t *testing.T) {
+	tests := []struct {
+		operator, value string
+		matched         []string
+	}{
+		{operator: "contains", value: "GO", matched: []string{"Go 1.24", "go fmt"}},
+		{operator: "equals", value: "go fmt", matched: []string{"go fmt"}},
+		{operator: "equals", value: "Go FMT", matched: []string{}},
+		{operator: "not-equals", value: "go fmt", matched: []string{"Go 1.24", "Rust 2.0"}},
+		{operator: "regex", value: `\d\.\d`, matched: []string{"Go 1.24", "Rust 2.0"}},
+	}
+
+	for _, tt := range tests {
+		config, err := engine.ParseConfig(`{"nodes":[{"id":"f","type":"filter","config":{"field":"title","operator":"` + tt.operator + `","value":"` + strings.ReplaceAll(tt.value, `\`, `\\`) + `"}}]}`)
+		if err != nil {
+			t.Fatalf("%s %q: %v", tt.operator, tt.value, err)
+		}
+
+		nodeConfig := config.Nodes[0].Config
+		if err := (&transforms.FilterNode{}).ValidateConfig(nodeConfig); err != nil {
+			t.Fatalf("%s %q: upgraded config isn't valid: %v", tt.operator, tt.value, err)
+		}
+
+		ports, _ := runFilter(t, nodeConfig, filterItems())
+		if got := titles(ports["matched"]); !reflect.DeepEqual(got, tt.matched) {
+			t.Errorf("%s %q matched %q, want %q", tt.operator, tt.value, got, tt.matched)
+		}
+		if n := len(ports["matched"]) + len(ports["unmatched"]); n != 3 {
+			t.Errorf("%s %q: %d items across both ports, want 3", tt.operator, tt.value, n)
+		}
+	}
+}
+
+func TestFilterDropMode(t *testing.T) {
+	for _, config := range []map[string]interface{}{
+		{"conditions": "title contains go", "action": "drop"},
+		{"mode": "expression", "expression": `contains(lower(title), "go")`, "action": "drop"},
+	} {
+		ports, _ := runFilter(t, config, filterItems())
+		if got, want := titles(ports["matched"]), []string{"Rust 2.0"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: matched %q, want %q", config, got, want)
+		}
+		if got, want := titles(ports["unmatched"]), []string{"Go 1.24", "go fmt"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: unmatched %q, want %q", config, got, want)
+		}
+	}
+}
+
+func TestFilterWithoutConditionsKeepsEverything(t *testing.T) {
+	for _, config := range []map[string]interface{}{
+		{},
+		{"conditions": "  \n"},
+		{"conditions": "", "action": "drop"},
+	} {
+		ports, _ := runFilter(t, config, filterItems())
+		if len(ports["matched"]) != 3 || len(ports["unmatched"]) != 0 {
+			t.Errorf("%v: matched %d, unmatched %d; want 3, 0", config, len(ports["matched"]), len(ports["unmatched"]))
+		}
+	}
+}
+
+func TestFilterExpression(t *testing.T) {
+	ports, preview := runFilter(t, map[string]interface{}{
+		"mode":       "expression",
+		"expression": "score / divisor > 4",
+	}, filterItems())
+
+	if got, want := titles(ports["matched"]), []string{"Go 1.24"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("matched %q, want %q", got, want)
+	}
+
+	// Division by zero fails on the last item, which is treated as not
+	// matching rather than failing the run
+	if got, want := titles(ports["unmatched"]), []string{"Rust 2.0", "go fmt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unmatched %q, want %q", got, want)
+	}
+
+	var warned bool
+	for _, log := range preview.Logs() {
+		if log.Level == "warn" && strings.Contains(log.Message, "failed on 1 of 3 items") && strings.Contains(log.Message, "division by zero") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Errorf("no warning about the failed expression in %v", preview.Logs())
+	}
+}
+
+func TestFilterInvalidConfig(t *testing.T) {
+	filter := &transforms.FilterNode{}
+	for _, config := range []map[string]interface{}{
+		{"mode": "expression"},
+		{"mode": "expression", "expression": "score >"},
+		{"conditions": "title sounds-like go"},
+	} {
+		if err := filter.ValidateConfig(config); err == nil {
+			t.Errorf("ValidateConfig(%v) succeeded, want error", config)
+		}
+		if _, err := filter.ExecutePorts(context.Background(), config, [][]interface{}{filterItems()}, &nodes.Context{Preview: &nodes.Preview{}}); err == nil {
+			t.Errorf("ExecutePorts(%v) succeeded, want error", config)
+		}
+	}
+}
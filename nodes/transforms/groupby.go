@@ -0,0 +1,270 @@
+package transforms
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+var aggregateCall = regexp.MustCompile(`^(\w+)\s*(?:\(\s*([^()]*?)\s*\))?$`)
+
+// aggregateFuncs maps each aggregate to whether it needs a field. count
+// without a field counts items; with one it counts items having the field.
+var aggregateFuncs = map[string]bool{
+	"count":    false,
+	"sum":      true,
+	"avg":      true,
+	"min":      true,
+	"max":      true,
+	"first":    true,
+	"last":     true,
+	"list":     true,
+	"distinct": true,
+}
+
+type GroupByNode struct{}
+
+type aggregate struct {
+	name  string
+	fn    string
+	field string
+}
+
+func (n *GroupByNode) Type() string        { return "group-by" }
+func (n *GroupByNode) Label() string       { return "Group By" }
+func (n *GroupByNode) Description() string { return "Summarize items with the same field values" }
+func (n *GroupByNode) Category() string    { return "transform" }
+func (n *GroupByNode) Inputs() int         { return 1 }
+func (n *GroupByNode) Outputs() int        { return 1 }
+
+func (n *GroupByNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	if len(inputs) == 0 || len(inputs[0]) == 0 {
+		return []interface{}{}, nil
+	}
+
+	fields := splitFields(config["group_by"])
+	aggregates, err := parseAggregates(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Groups are output in the order they first appear
+	var order []string
+	groups := make(map[string][]map[string]interface{})
+
+	for _, item := range inputs[0] {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		key := groupKey(itemMap, fields)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], itemMap)
+	}
+
+	result := make([]interface{}, 0, len(order))
+	for _, key := range order {
+		members := groups[key]
+
+		record := make(map[string]interface{})
+		for _, field := range fields {
+			setNestedValue(record, field, getNestedValue(members[0], field))
+		}
+		for _, agg := range aggregates {
+			setNestedValue(record, agg.name, agg.apply(members))
+		}
+
+		result = append(result, record)
+	}
+
+	execCtx.Log("group-by", "info", fmt.Sprintf("Grouped %d items into %d groups", len(inputs[0]), len(result)))
+
+	return result, nil
+}
+
+// groupKey identifies an item's group. Missing fields form their own group,
+// separate from empty strings.
+func groupKey(item map[string]interface{}, fields []string) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		if v := getNestedValue(item, field); v != nil {
+			parts[i] = "=" + valueString(v)
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// parseAggregates reads one "name = function(field)" per line, skipping
+// blank lines and lines starting with #.
+func parseAggregates(config map[string]interface{}) ([]aggregate, error) {
+	text, _ := config["aggregates"].(string)
+
+	var aggregates []aggregate
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, call, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"name = function(field)\"", i+1)
+		}
+
+		name = strings.TrimSpace(name)
+		if !computeFieldName.MatchString(name) {
+			return nil, fmt.Errorf("line %d: invalid field name %q", i+1, name)
+		}
+
+		m := aggregateCall.FindStringSubmatch(strings.TrimSpace(call))
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected \"name = function(field)\"", i+1)
+		}
+
+		fn := strings.ToLower(m[1])
+		needsField, known := aggregateFuncs[fn]
+		if !known {
+			return nil, fmt.Errorf("line %d: unknown function %q", i+1, m[1])
+		}
+		if needsField && m[2] == "" {
+			return nil, fmt.Errorf("line %d: %s needs a field, e.g. %s(score)", i+1, fn, fn)
+		}
+
+		aggregates = append(aggregates, aggregate{name: name, fn: fn, field: m[2]})
+	}
+
+	return aggregates, nil
+}
+
+// apply computes the aggregate over a group's items. Items without the
+// field are ignored, and functions with nothing to work on give null.
+func (a *aggregate) apply(items []map[string]interface{}) interface{} {
+	if a.fn == "count" && a.field == "" {
+		return len(items)
+	}
+
+	var values []interface{}
+	for _, item := range items {
+		if v := getNestedValue(item, a.field); v != nil {
+			values = append(values, v)
+		}
+	}
+
+	switch a.fn {
+	case "count":
+		return len(values)
+
+	case "sum", "avg":
+		sum, n := 0.0, 0
+		for _, v := range values {
+			if f, ok := toNumber(v); ok {
+				sum += f
+				n++
+			}
+		}
+		if a.fn == "sum" {
+			return sum
+		}
+		if n == 0 {
+			return nil
+		}
+		return sum / float64(n)
+
+	case "min", "max":
+		var best interface{}
+		for _, v := range values {
+			if best == nil {
+				best = v
+				continue
+			}
+			if c := compareValues(v, best); (a.fn == "min" && c < 0) || (a.fn == "max" && c > 0) {
+				best = v
+			}
+		}
+		return best
+
+	case "first":
+		if len(values) == 0 {
+			return nil
+		}
+		return values[0]
+
+	case "last":
+		if len(values) == 0 {
+			return nil
+		}
+		return values[len(values)-1]
+
+	case "list":
+		if values == nil {
+			return []interface{}{}
+		}
+		return values
+
+	case "distinct":
+		seen := make(map[string]bool)
+		for _, v := range values {
+			seen[valueString(v)] = true
+		}
+		return len(seen)
+	}
+
+	return nil
+}
+
+// compareValues orders numbers and dates as such, and anything else as
+// text.
+func compareValues(a, b interface{}) int {
+	if x, ok := toComparable(a); ok {
+		if y, ok := toComparable(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(valueString(a), valueString(b))
+}
+
+func (n *GroupByNode) ValidateConfig(config map[string]interface{}) error {
+	aggregates, err := parseAggregates(config)
+	if err != nil {
+		return err
+	}
+	if len(aggregates) == 0 && len(splitFields(config["group_by"])) == 0 {
+		return fmt.Errorf("set fields to group by or at least one aggregate")
+	}
+	return nil
+}
+
+func (n *GroupByNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:        "group_by",
+				Label:       "Group By",
+				Type:        "text",
+				Required:    false,
+				Placeholder: "author.name",
+				HelpText:    "Field(s) to group on, comma-separated (dot notation allowed). Leave empty to summarize all items as one group.",
+			},
+			{
+				Name:        "aggregates",
+				Label:       "Aggregates",
+				Type:        "textarea",
+				Required:    false,
+				Placeholder: "posts = count\ntotal_score = sum(score)\nlatest = max(published_at)\ntitles = list(title)",
+				HelpText:    "One per line: name = function(field). Functions: count, sum, avg, min, max, first, last, list, distinct (number of different values).",
+			},
+		},
+	}
+}
@@ -0,0 +1,218 @@
+package transforms
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+func TestParseAggregates(t *testing.T) {
+	aggregates, err := parseAggregates(map[string]interface{}{"aggregates": `
+		# comments and blank lines are skipped
+
+		posts = count
+		with_tags = COUNT( tags )
+		stats.total = sum(stats.views)
+		titles=list(title)
+	`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []aggregate{
+		{name: "posts", fn: "count"},
+		{name: "with_tags", fn: "count", field: "tags"},
+		{name: "stats.total", fn: "sum", field: "stats.views"},
+		{name: "titles", fn: "list", field: "title"},
+	}
+	if !reflect.DeepEqual(aggregates, want) {
+		t.Errorf("got %+v, want %+v", aggregates, want)
+	}
+
+	if aggregates, err := parseAggregates(map[string]interface{}{}); err != nil || aggregates != nil {
+		t.Errorf("no aggregates = %v, %v; want nil, nil", aggregates, err)
+	}
+}
+
+func TestParseAggregatesErrors(t *testing.T) {
+	tests := []struct {
+		text    string
+		wantErr string
+	}{
+		{text: "count", wantErr: `line 1: expected "name = function(field)"`},
+		{text: "= count", wantErr: `line 1: invalid field name ""`},
+		{text: "two words = count", wantErr: `invalid field name "two words"`},
+		{text: "posts = count\ntotal = sum(a, b)(c)", wantErr: `line 2: expected "name = function(field)"`},
+		{text: "total = sum(", wantErr: `expected "name = function(field)"`},
+		{text: "middle = median(score)", wantErr: `line 1: unknown function "median"`},
+		{text: "total = sum", wantErr: "line 1: sum needs a field, e.g. sum(score)"},
+		{text: "total = sum()", wantErr: "sum needs a field"},
+		{text: "titles = list", wantErr: "list needs a field"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseAggregates(map[string]interface{}{"aggregates": tt.text})
+		if err == nil {
+			t.Errorf("parseAggregates(%q) succeeded, want error %q", tt.text, tt.wantErr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseAggregates(%q) error = %q, want %q", tt.text, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAggregateApply(t *testing.T) {
+	posts := []map[string]interface{}{
+		{"score": float64(3), "tag": "x", "published": "2026-01-02", "stats": map[string]interface{}{"views": float64(5)}},
+		{"score": "10", "tag": "x", "published": "2026-01-10"},
+		{"score": float64(9), "published": "2025-12-31", "stats": map[string]interface{}{"views": float64(7)}},
+		{"score": "n/a", "tag": "y"},
+	}
+	mixed := []map[string]interface{}{
+		{"value": float64(9)},
+		{"value": "10"},
+		{"value": "apple"},
+		{"value": float64(2)},
+	}
+
+	tests := []struct {
+		agg   string
+		items []map[string]interface{}
+		want  interface{}
+	}{
+		{agg: "count", items: posts, want: 4},
+		{agg: "count(tag)", items: posts, want: 3},
+		{agg: "sum(score)", items: posts, want: float64(22)},
+		{agg: "sum(stats.views)", items: posts, want: float64(12)},
+		{agg: "avg(score)", items: posts, want: float64(22) / 3},
+		{agg: "min(score)", items: posts[:3], want: float64(3)},
+		{agg: "max(score)", items: posts[:3], want: "10"},
+		{agg: "min(published)", items: posts, want: "2025-12-31"},
+		{agg: "max(published)", items: posts, want: "2026-01-10"},
+		{agg: "first(tag)", items: posts, want: "x"},
+		{agg: "last(tag)", items: posts, want: "y"},
+		{agg: "first(stats.views)", items: posts[1:], want: float64(7)},
+		{agg: "list(tag)", items: posts, want: []interface{}{"x", "x", "y"}},
+		{agg: "distinct(tag)", items: posts, want: 2},
+		{agg: "distinct(value)", items: []map[string]interface{}{{"value": float64(1)}, {"value": "1"}, {"value": 1.5}}, want: 2},
+
+		// Numeric strings compare as numbers, and other text against
+		// numbers as text, where letters sort after digits
+		{agg: "min(value)", items: mixed, want: float64(2)},
+		{agg: "max(value)", items: mixed, want: "apple"},
+		{agg: "max(value)", items: mixed[:2], want: "10"},
+		{agg: "min(value)", items: mixed[2:], want: float64(2)},
+
+		// Items without the field are ignored
+		{agg: "count(missing)", items: posts, want: 0},
+		{agg: "sum(missing)", items: posts, want: float64(0)},
+		{agg: "avg(missing)", items: posts, want: nil},
+		{agg: "avg(tag)", items: posts, want: nil},
+		{agg: "min(missing)", items: posts, want: nil},
+		{agg: "max(missing)", items: posts, want: nil},
+		{agg: "first(missing)", items: posts, want: nil},
+		{agg: "last(missing)", items: posts, want: nil},
+		{agg: "list(missing)", items: posts, want: []interface{}{}},
+		{agg: "distinct(missing)", items: posts, want: 0},
+
+		// Empty groups
+		{agg: "count", items: nil, want: 0},
+		{agg: "count(tag)", items: nil, want: 0},
+		{agg: "sum(score)", items: nil, want: float64(0)},
+		{agg: "avg(score)", items: nil, want: nil},
+		{agg: "min(score)", items: nil, want: nil},
+		{agg: "max(score)", items: nil, want: nil},
+		{agg: "first(score)", items: nil, want: nil},
+		{agg: "last(score)", items: nil, want: nil},
+		{agg: "list(score)", items: nil, want: []interface{}{}},
+		{agg: "distinct(score)", items: nil, want: 0},
+	}
+
+	for _, tt := range tests {
+		aggregates, err := parseAggregates(map[string]interface{}{"aggregates": "result = " + tt.agg})
+		if err != nil {
+			t.Errorf("%s: %v", tt.agg, err)
+			continue
+		}
+		if got := aggregates[0].apply(tt.items); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s over %v = %#v, want %#v", tt.agg, tt.items, got, tt.want)
+		}
+	}
+}
+
+func TestGroupByExecute(t *testing.T) {
+	config := map[string]interface{}{
+		"group_by":   "tag",
+		"aggregates": "posts = count\ntotal = sum(score)",
+	}
+	items := []interface{}{
+		map[string]interface{}{"tag": "x", "score": float64(1)},
+		map[string]interface{}{"score": float64(2)},
+		map[string]interface{}{"tag": "", "score": float64(3)},
+		"not an object",
+		map[string]interface{}{"tag": "x", "score": float64(4)},
+	}
+
+	preview := &nodes.Preview{}
+	result, err := (&GroupByNode{}).Execute(context.Background(), config, [][]interface{}{items}, &nodes.Context{Preview: preview})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Groups keep the order they first appear in, and items missing the
+	// field are grouped apart from empty strings
+	want := []struct {
+		tag   interface{}
+		posts int
+		total float64
+	}{
+		{tag: "x", posts: 2, total: 5},
+		{tag: nil, posts: 1, total: 2},
+		{tag: "", posts: 1, total: 3},
+	}
+	if len(result) != len(want) {
+		t.Fatalf("got %d groups %v, want %d", len(result), result, len(want))
+	}
+	for i, w := range want {
+		record := result[i].(map[string]interface{})
+		if record["tag"] != w.tag || record["posts"] != w.posts || record["total"] != w.total {
+			t.Errorf("group %d = %v, want tag %v, %d posts, total %v", i, record, w.tag, w.posts, w.total)
+		}
+	}
+
+	// Without group fields every item is one group
+	result, err = (&GroupByNode{}).Execute(context.Background(), map[string]interface{}{"aggregates": "posts = count"}, [][]interface{}{items}, &nodes.Context{Preview: preview})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].(map[string]interface{})["posts"] != 4 {
+		t.Errorf("ungrouped result = %v, want one group of 4 posts", result)
+	}
+
+	result, err = (&GroupByNode{}).Execute(context.Background(), config, [][]interface{}{{}}, &nodes.Context{Preview: preview})
+	if err != nil || len(result) != 0 {
+		t.Errorf("no items = %v, %v; want no groups", result, err)
+	}
+}
+
+func TestGroupByValidateConfig(t *testing.T) {
+	node := &GroupByNode{}
+	if err := node.ValidateConfig(map[string]interface{}{}); err == nil {
+		t.Error("empty config is valid, want error")
+	}
+	if err := node.ValidateConfig(map[string]interface{}{"group_by": "tag", "aggregates": "total = sum"}); err == nil {
+		t.Error("aggregate without a field is valid, want error")
+	}
+	for _, config := range []map[string]interface{}{
+		{"group_by": " tag, author.name "},
+		{"aggregates": "posts = count"},
+	} {
+		if err := node.ValidateConfig(config); err != nil {
+			t.Errorf("ValidateConfig(%v): %v", config, err)
+		}
+	}
+}
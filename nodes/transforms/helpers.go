@@ -35,3 +35,24 @@ func toFloat(v interface{}) (float64, bool) {
 		return 0, false
 	}
 }
+
+// setNestedValue sets a value in a map using dot notation, creating
+// intermediate maps as needed. Nested maps along the path are copied rather
+// than modified, since they may be shared with other items.
+func setNestedValue(obj map[string]interface{}, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	current := obj
+
+	for _, part := range parts[:len(parts)-1] {
+		next := make(map[string]interface{})
+		if m, ok := current[part].(map[string]interface{}); ok {
+			for k, v := range m {
+				next[k] = v
+			}
+		}
+		current[part] = next
+		current = next
+	}
+
+	current[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,244 @@
+package transforms
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+const defaultJoinPrefix = "right_"
+
+type JoinNode struct{}
+
+func (n *JoinNode) Type() string        { return "join" }
+func (n *JoinNode) Label() string       { return "Join" }
+func (n *JoinNode) Description() string { return "Combine left and right items with matching keys" }
+func (n *JoinNode) Category() string    { return "transform" }
+func (n *JoinNode) Inputs() int         { return 2 }
+func (n *JoinNode) Outputs() int        { return 1 }
+
+func (n *JoinNode) InputPorts() []string {
+	return []string{"left", "right"}
+}
+
+func (n *JoinNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	var left, right []interface{}
+	if len(inputs) > 0 {
+		left = inputs[0]
+	}
+	if len(inputs) > 1 {
+		right = inputs[1]
+	}
+
+	leftKeys, rightKeys, err := joinKeys(config)
+	if err != nil {
+		return nil, err
+	}
+
+	joinType, _ := config["join_type"].(string)
+	if joinType == "" {
+		joinType = "inner"
+	}
+
+	prefix, ok := config["prefix"].(string)
+	if !ok || prefix == "" {
+		prefix = defaultJoinPrefix
+	}
+
+	// Index the right side by key
+	index := make(map[string][]int)
+	for i, item := range right {
+		if key, ok := joinKey(item, rightKeys); ok {
+			index[key] = append(index[key], i)
+		}
+	}
+
+	result := []interface{}{}
+	matchedRight := make([]bool, len(right))
+	matched := 0
+
+	for _, item := range left {
+		var matches []int
+		if key, ok := joinKey(item, leftKeys); ok {
+			matches = index[key]
+		}
+
+		if len(matches) == 0 {
+			if joinType != "inner" {
+				result = append(result, item)
+			}
+			continue
+		}
+
+		matched++
+		for _, i := range matches {
+			matchedRight[i] = true
+			result = append(result, joinItems(item, right[i], prefix))
+		}
+	}
+
+	if joinType == "full" {
+		for i, item := range right {
+			if !matchedRight[i] {
+				result = append(result, item)
+			}
+		}
+	}
+
+	execCtx.Log("join", "info", fmt.Sprintf("%s join: %d of %d left items matched %d right items, %d results", joinType, matched, len(left), len(right), len(result)))
+
+	return result, nil
+}
+
+// joinKeys returns the key fields for each side. The right side uses the
+// left's fields unless it sets its own.
+func joinKeys(config map[string]interface{}) ([]string, []string, error) {
+	leftKeys := splitFields(config["left_key"])
+	if len(leftKeys) == 0 {
+		return nil, nil, fmt.Errorf("left_key is required")
+	}
+
+	rightKeys := splitFields(config["right_key"])
+	if len(rightKeys) == 0 {
+		rightKeys = leftKeys
+	}
+
+	if len(leftKeys) != len(rightKeys) {
+		return nil, nil, fmt.Errorf("left_key has %d fields but right_key has %d", len(leftKeys), len(rightKeys))
+	}
+
+	return leftKeys, rightKeys, nil
+}
+
+// splitFields reads a comma-separated list of field paths.
+func splitFields(v interface{}) []string {
+	s, _ := v.(string)
+
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// joinKey builds an item's key from its key fields. Items missing any key
+// field have no key and match nothing.
+func joinKey(item interface{}, fields []string) (string, bool) {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		v := getNestedValue(itemMap, field)
+		if v == nil {
+			return "", false
+		}
+		parts[i] = valueString(v)
+	}
+	return strings.Join(parts, "\x00"), true
+}
+
+// joinItems merges a right item into a copy of a left item. Right fields
+// that would overwrite a different left value are added with the prefix
+// instead, numbered (right_title_2, ...) if that name is taken too.
+func joinItems(left, right interface{}, prefix string) interface{} {
+	leftMap, lok := left.(map[string]interface{})
+	rightMap, rok := right.(map[string]interface{})
+	if !lok || !rok {
+		return left
+	}
+
+	merged := make(map[string]interface{}, len(leftMap)+len(rightMap))
+	for k, v := range leftMap {
+		merged[k] = v
+	}
+
+	var conflicts []string
+	for k, v := range rightMap {
+		existing, conflict := leftMap[k]
+		if !conflict {
+			merged[k] = v
+		} else if valueString(existing) != valueString(v) {
+			conflicts = append(conflicts, k)
+		}
+	}
+
+	// Renamed once every other name is in place, in name order so the
+	// numbering is the same on every run
+	sort.Strings(conflicts)
+	for _, k := range conflicts {
+		name := prefix + k
+		for i := 2; ; i++ {
+			if _, taken := merged[name]; !taken {
+				break
+			}
+			name = fmt.Sprintf("%s%s_%d", prefix, k, i)
+		}
+		merged[name] = rightMap[k]
+	}
+
+	return merged
+}
+
+func (n *JoinNode) ValidateConfig(config map[string]interface{}) error {
+	if _, _, err := joinKeys(config); err != nil {
+		return err
+	}
+
+	switch joinType, _ := config["join_type"].(string); joinType {
+	case "", "inner", "left", "full":
+	default:
+		return fmt.Errorf("unknown join type %q", joinType)
+	}
+	return nil
+}
+
+func (n *JoinNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:     "join_type",
+				Label:    "Join Type",
+				Type:     "select",
+				Required: false,
+				Options: []nodes.FieldOption{
+					{Value: "inner", Label: "Inner (only matches)"},
+					{Value: "left", Label: "Left (all left items)"},
+					{Value: "full", Label: "Full (all items)"},
+				},
+				HelpText: "Which unmatched items to keep",
+			},
+			{
+				Name:        "left_key",
+				Label:       "Left Key",
+				Type:        "text",
+				Required:    true,
+				Placeholder: "link",
+				HelpText:    "Field(s) identifying left items, comma-separated (dot notation allowed)",
+			},
+			{
+				Name:        "right_key",
+				Label:       "Right Key",
+				Type:        "text",
+				Required:    false,
+				Placeholder: "url",
+				HelpText:    "Matching field(s) of right items, in the same order. Defaults to the left key.",
+			},
+			{
+				Name:         "prefix",
+				Label:        "Conflict Prefix",
+				Type:         "text",
+				Required:     false,
+				DefaultValue: defaultJoinPrefix,
+				HelpText:     "Prefix for right fields whose name is already used by a different left value (numbered if the prefixed name is taken too)",
+			},
+		},
+	}
+}
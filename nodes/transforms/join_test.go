@@ -0,0 +1,216 @@
+package transforms
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+func runJoin(t *testing.T, config map[string]interface{}, left, right []interface{}) []interface{} {
+	t.Helper()
+	result, err := (&JoinNode{}).Execute(context.Background(), config, [][]interface{}{left, right}, &nodes.Context{Preview: &nodes.Preview{}})
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	return result
+}
+
+func TestJoinTypes(t *testing.T) {
+	left := []interface{}{
+		map[string]interface{}{"id": "a", "title": "A"},
+		map[string]interface{}{"id": "b", "title": "B"},
+		map[string]interface{}{"title": "no id"},
+		map[string]interface{}{"id": "c", "title": "C"},
+	}
+	right := []interface{}{
+		map[string]interface{}{"id": "c", "score": float64(3)},
+		map[string]interface{}{"id": "a", "score": float64(1)},
+		map[string]interface{}{"id": "z", "score": float64(26)},
+		map[string]interface{}{"id": "a", "score": float64(2)},
+		map[string]interface{}{"score": float64(0)},
+	}
+
+	matches := []interface{}{
+		map[string]interface{}{"id": "a", "title": "A", "score": float64(1)},
+		map[string]interface{}{"id": "a", "title": "A", "score": float64(2)},
+		map[string]interface{}{"id": "c", "title": "C", "score": float64(3)},
+	}
+
+	tests := []struct {
+		joinType string
+		want     []interface{}
+	}{
+		{
+			joinType: "",
+			want:     matches,
+		},
+		{
+			joinType: "inner",
+			want:     matches,
+		},
+		{
+			// Unmatched left items keep their place
+			joinType: "left",
+			want: []interface{}{
+				matches[0], matches[1],
+				map[string]interface{}{"id": "b", "title": "B"},
+				map[string]interface{}{"title": "no id"},
+				matches[2],
+			},
+		},
+		{
+			// Unmatched right items come after the others
+			joinType: "full",
+			want: []interface{}{
+				matches[0], matches[1],
+				map[string]interface{}{"id": "b", "title": "B"},
+				map[string]interface{}{"title": "no id"},
+				matches[2],
+				map[string]interface{}{"id": "z", "score": float64(26)},
+				map[string]interface{}{"score": float64(0)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := runJoin(t, map[string]interface{}{"join_type": tt.joinType, "left_key": "id"}, left, right)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q join = %v, want %v", tt.joinType, got, tt.want)
+		}
+	}
+}
+
+func TestJoinKeys(t *testing.T) {
+	left := []interface{}{
+		map[string]interface{}{"feed": "go", "guid": float64(1), "title": "Go 1"},
+		map[string]interface{}{"feed": "go", "guid": float64(2), "title": "Go 2"},
+		map[string]interface{}{"feed": "rust", "guid": float64(1), "title": "Rust 1"},
+		map[string]interface{}{"feed": "rust", "title": "Rust ?"},
+	}
+	right := []interface{}{
+		map[string]interface{}{"source": map[string]interface{}{"name": "rust", "id": "1"}, "stars": float64(5)},
+		map[string]interface{}{"source": map[string]interface{}{"name": "go", "id": "2"}, "stars": float64(7)},
+		map[string]interface{}{"source": map[string]interface{}{"name": "go"}, "stars": float64(9)},
+		map[string]interface{}{"source": "go", "stars": float64(11)},
+	}
+
+	// Both fields must match, numbers equal their text, and items missing a
+	// key field match nothing
+	got := runJoin(t, map[string]interface{}{"join_type": "left", "left_key": "feed, guid", "right_key": "source.name,source.id"}, left, right)
+	want := []interface{}{
+		map[string]interface{}{"feed": "go", "guid": float64(1), "title": "Go 1"},
+		map[string]interface{}{"feed": "go", "guid": float64(2), "title": "Go 2", "source": map[string]interface{}{"name": "go", "id": "2"}, "stars": float64(7)},
+		map[string]interface{}{"feed": "rust", "guid": float64(1), "title": "Rust 1", "source": map[string]interface{}{"name": "rust", "id": "1"}, "stars": float64(5)},
+		map[string]interface{}{"feed": "rust", "title": "Rust ?"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("composite join = %v, want %v", got, want)
+	}
+
+	// Dot paths work on the left too, and the right defaults to the same key
+	got = runJoin(t, map[string]interface{}{"left_key": "source.name"}, []interface{}{map[string]interface{}{"source": map[string]interface{}{"name": "rust"}, "title": "Rust"}}, right)
+	if len(got) != 1 || got[0].(map[string]interface{})["stars"] != float64(5) {
+		t.Errorf("dot path join = %v, want Rust with 5 stars", got)
+	}
+
+	// Items that aren't objects have no key
+	got = runJoin(t, map[string]interface{}{"join_type": "full", "left_key": "id"}, []interface{}{"a", map[string]interface{}{"id": "a"}}, []interface{}{map[string]interface{}{"id": "a", "n": float64(1)}, "b"})
+	want = []interface{}{"a", map[string]interface{}{"id": "a", "n": float64(1)}, "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("join with non-objects = %v, want %v", got, want)
+	}
+}
+
+func TestJoinConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		left   map[string]interface{}
+		right  map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{
+			name:  "equal values are kept once",
+			left:  map[string]interface{}{"id": "a", "title": "Go", "score": float64(1)},
+			right: map[string]interface{}{"id": "a", "title": "Go", "score": "1"},
+			want:  map[string]interface{}{"id": "a", "title": "Go", "score": float64(1)},
+		},
+		{
+			name:  "different values get the default prefix",
+			left:  map[string]interface{}{"id": "a", "title": "Go"},
+			right: map[string]interface{}{"id": "a", "title": "Golang", "stars": float64(3)},
+			want:  map[string]interface{}{"id": "a", "title": "Go", "right_title": "Golang", "stars": float64(3)},
+		},
+		{
+			name:   "custom prefix",
+			prefix: "hn_",
+			left:   map[string]interface{}{"id": "a", "title": "Go"},
+			right:  map[string]interface{}{"id": "a", "title": "Golang"},
+			want:   map[string]interface{}{"id": "a", "title": "Go", "hn_title": "Golang"},
+		},
+		{
+			name:  "prefixed name used by the left",
+			left:  map[string]interface{}{"id": "a", "title": "Go", "right_title": "kept", "right_title_2": "kept too"},
+			right: map[string]interface{}{"id": "a", "title": "Golang"},
+			want:  map[string]interface{}{"id": "a", "title": "Go", "right_title": "kept", "right_title_2": "kept too", "right_title_3": "Golang"},
+		},
+		{
+			name:  "prefixed name used by the right",
+			left:  map[string]interface{}{"id": "a", "title": "Go"},
+			right: map[string]interface{}{"id": "a", "title": "Golang", "right_title": "from the right"},
+			want:  map[string]interface{}{"id": "a", "title": "Go", "right_title": "from the right", "right_title_2": "Golang"},
+		},
+		{
+			name:   "prefixed names of two fields",
+			prefix: "r",
+			left:   map[string]interface{}{"id": "a", "x": "1", "rx": "2"},
+			right:  map[string]interface{}{"id": "a", "x": "3", "rx": "4"},
+			want:   map[string]interface{}{"id": "a", "x": "1", "rx": "2", "rrx": "4", "rx_2": "3"},
+		},
+	}
+
+	for _, tt := range tests {
+		left := make(map[string]interface{})
+		for k, v := range tt.left {
+			left[k] = v
+		}
+
+		got := runJoin(t, map[string]interface{}{"left_key": "id", "prefix": tt.prefix}, []interface{}{tt.left}, []interface{}{tt.right})
+		if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(tt.left, left) {
+			t.Errorf("%s: left item changed to %v", tt.name, tt.left)
+		}
+	}
+}
+
+func TestJoinValidateConfig(t *testing.T) {
+	tests := []struct {
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{config: map[string]interface{}{"left_key": "id"}},
+		{config: map[string]interface{}{"left_key": "a, b", "right_key": "x,y", "join_type": "full"}},
+		{config: map[string]interface{}{}, wantErr: "left_key is required"},
+		{config: map[string]interface{}{"left_key": " , ", "right_key": "id"}, wantErr: "left_key is required"},
+		{config: map[string]interface{}{"left_key": "a,b", "right_key": "x"}, wantErr: "left_key has 2 fields but right_key has 1"},
+		{config: map[string]interface{}{"left_key": "id", "join_type": "outer"}, wantErr: `unknown join type "outer"`},
+	}
+
+	for _, tt := range tests {
+		err := (&JoinNode{}).ValidateConfig(tt.config)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateConfig(%v): %v", tt.config, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateConfig(%v) = %v, want %q", tt.config, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package transforms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+const (
+	newItemsInitializedKey  = "initialized"
+	defaultNewItemsTTLHours = 720
+)
+
+type NewItemsNode struct{}
+
+func (n *NewItemsNode) Type() string  { return "new-items" }
+func (n *NewItemsNode) Label() string { return "New Items Only" }
+func (n *NewItemsNode) Description() string {
+	return "Pass through only items not seen in previous runs"
+}
+func (n *NewItemsNode) Category() string { return "transform" }
+func (n *NewItemsNode) Inputs() int      { return 1 }
+func (n *NewItemsNode) Outputs() int     { return 1 }
+
+func (n *NewItemsNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	if len(inputs) == 0 {
+		return []interface{}{}, nil
+	}
+
+	items := inputs[0]
+	fields := newItemsKeyFields(config)
+
+	hours, ok := nodes.ConfigNumber(config, "ttl_hours")
+	if !ok {
+		hours = defaultNewItemsTTLHours
+	}
+	ttl := time.Duration(hours * float64(time.Hour))
+
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = itemKey(item, fields)
+	}
+
+	seen, err := execCtx.SeenItems(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	_, initialized, err := execCtx.GetState(newItemsInitializedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every current item is marked, so items still in the feed don't expire
+	// and reappear as new
+	execCtx.MarkSeen(keys, ttl)
+	execCtx.SetState(newItemsInitializedKey, "true")
+
+	if !initialized && config["first_run"] == "skip" {
+		execCtx.Log("new-items", "info", fmt.Sprintf("First run: recorded %d items without emitting them", len(items)))
+		return []interface{}{}, nil
+	}
+
+	result := []interface{}{}
+	emitted := make(map[string]bool)
+	for i, item := range items {
+		if seen[keys[i]] || emitted[keys[i]] {
+			continue
+		}
+		emitted[keys[i]] = true
+		result = append(result, item)
+	}
+
+	execCtx.Log("new-items", "info", fmt.Sprintf("%d of %d items are new", len(result), len(items)))
+
+	return result, nil
+}
+
+func newItemsKeyFields(config map[string]interface{}) []string {
+	keyField, _ := config["key_field"].(string)
+	if strings.TrimSpace(keyField) == "" {
+		keyField = "guid,link"
+	}
+
+	var fields []string
+	for _, f := range strings.Split(keyField, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// itemKey identifies an item by the first non-empty key field, falling back
+// to a hash of the whole item.
+func itemKey(item interface{}, fields []string) string {
+	if m, ok := item.(map[string]interface{}); ok {
+		for _, field := range fields {
+			if v := getNestedValue(m, field); v != nil && fmt.Sprintf("%v", v) != "" {
+				return fmt.Sprintf("%s:%v", field, v)
+			}
+		}
+	}
+
+	data, _ := json.Marshal(item)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (n *NewItemsNode) ValidateConfig(config map[string]interface{}) error {
+	if v, ok := config["ttl_hours"]; ok && v != "" {
+		if hours, ok := nodes.ConfigNumber(config, "ttl_hours"); !ok || hours < 0 {
+			return fmt.Errorf("ttl_hours must be a non-negative number")
+		}
+	}
+	return nil
+}
+
+func (n *NewItemsNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:         "key_field",
+				Label:        "Key Fields",
+				Type:         "text",
+				Required:     false,
+				DefaultValue: "guid,link",
+				Placeholder:  "guid,link",
+				HelpText:     "Fields that identify an item, tried in order (dot notation allowed). Items without any are identified by their content.",
+			},
+			{
+				Name:         "ttl_hours",
+				Label:        "Remember For (hours)",
+				Type:         "number",
+				Required:     false,
+				DefaultValue: defaultNewItemsTTLHours,
+				HelpText:     "Forget items not seen for this long (0 = forever)",
+			},
+			{
+				Name:     "first_run",
+				Label:    "First Run",
+				Type:     "select",
+				Required: false,
+				Options: []nodes.FieldOption{
+					{Value: "emit", Label: "Emit all items"},
+					{Value: "skip", Label: "Only record items"},
+				},
+				HelpText: "What to do the first time the pipe runs, when every item is new",
+			},
+		},
+	}
+}
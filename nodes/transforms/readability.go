@@ -0,0 +1,334 @@
+package transforms
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Readability-style article extraction: paragraphs are scored by length and
+// commas, their scores are credited to their parent and grandparent, and
+// the best-scoring container (discounted by how much of it is links) is
+// taken as the article, along with siblings that look like part of it.
+
+var (
+	unlikelyCandidates = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|header|legends|menu|modal|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|ad-break|agegate|pagination|pager|popup|newsletter|promo`)
+	maybeCandidate     = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow|story|entry|post`)
+	positiveClass      = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeClass      = regexp.MustCompile(`(?i)hidden|^hid$|banner|combx|comment|com-|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget|byline|author`)
+	bylineClass        = regexp.MustCompile(`(?i)byline|author|writtenby|p-author`)
+)
+
+// Elements that never hold article content
+const junkSelector = "script, style, noscript, iframe, form, nav, aside, svg, button, input, select, textarea, template"
+
+// Attributes kept on the extracted content
+var keptAttributes = map[string]bool{
+	"href":  true,
+	"src":   true,
+	"alt":   true,
+	"title": true,
+}
+
+const minParagraphLength = 25
+
+type article struct {
+	title   string
+	byline  string
+	image   string
+	content string
+	length  int
+}
+
+// extractArticle finds a page's main content and metadata. Links and
+// images in the content are made absolute against base.
+func extractArticle(doc *goquery.Document, base *url.URL) (*article, error) {
+	a := &article{
+		title:  articleTitle(doc),
+		byline: articleByline(doc),
+		image:  metaContent(doc, `meta[property="og:image"], meta[name="twitter:image"]`),
+	}
+
+	doc.Find(junkSelector).Remove()
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		if s.Is("html, body, article, main") {
+			return
+		}
+		class, _ := s.Attr("class")
+		id, _ := s.Attr("id")
+		match := class + " " + id
+		if unlikelyCandidates.MatchString(match) && !maybeCandidate.MatchString(match) {
+			s.Remove()
+		}
+	})
+
+	top := topCandidate(doc)
+	if top == nil {
+		return nil, fmt.Errorf("no article content found")
+	}
+
+	content := articleContent(top)
+	cleanContent(content, base)
+
+	out, err := goquery.OuterHtml(content)
+	if err != nil {
+		return nil, fmt.Errorf("render content: %w", err)
+	}
+
+	a.content = out
+	a.length = len([]rune(normalizeSpace(content.Text())))
+	if a.length == 0 {
+		return nil, fmt.Errorf("no article content found")
+	}
+
+	if a.image == "" {
+		a.image, _ = content.Find("img[src]").First().Attr("src")
+	} else if u, err := base.Parse(a.image); err == nil {
+		a.image = u.String()
+	}
+
+	return a, nil
+}
+
+// topCandidate scores the page's paragraphs and returns the best container.
+func topCandidate(doc *goquery.Document) *goquery.Selection {
+	scores := make(map[*html.Node]float64)
+	var candidates []*goquery.Selection
+
+	addCandidate := func(s *goquery.Selection) {
+		node := s.Get(0)
+		if _, ok := scores[node]; !ok {
+			scores[node] = initialScore(s)
+			candidates = append(candidates, s)
+		}
+	}
+
+	doc.Find("p, pre, td, blockquote").Each(func(i int, p *goquery.Selection) {
+		text := normalizeSpace(p.Text())
+		if len(text) < minParagraphLength {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+
+		parent := p.Parent()
+		if parent.Length() == 0 || parent.Is("html") {
+			return
+		}
+		addCandidate(parent)
+		scores[parent.Get(0)] += score
+
+		if grandparent := parent.Parent(); grandparent.Length() > 0 && !grandparent.Is("html") {
+			addCandidate(grandparent)
+			scores[grandparent.Get(0)] += score / 2
+		}
+	})
+
+	var best *goquery.Selection
+	bestScore := 0.0
+	for _, s := range candidates {
+		score := scores[s.Get(0)] * (1 - linkDensity(s))
+		if best == nil || score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+
+	if best == nil {
+		// No paragraphs to go on; fall back to the whole body
+		if body := doc.Find("body"); body.Length() > 0 {
+			return body
+		}
+		return nil
+	}
+
+	return best
+}
+
+func initialScore(s *goquery.Selection) float64 {
+	var score float64
+	switch goquery.NodeName(s) {
+	case "article":
+		score = 10
+	case "div", "main", "section":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+	return score + classWeight(s)
+}
+
+// classWeight rewards class names and IDs that suggest content and
+// penalizes ones that suggest page furniture.
+func classWeight(s *goquery.Selection) float64 {
+	var weight float64
+	for _, attr := range []string{"class", "id"} {
+		v, _ := s.Attr(attr)
+		if v == "" {
+			continue
+		}
+		if negativeClass.MatchString(v) {
+			weight -= 25
+		}
+		if positiveClass.MatchString(v) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity is the fraction of an element's text that is inside links.
+func linkDensity(s *goquery.Selection) float64 {
+	length := len(normalizeSpace(s.Text()))
+	if length == 0 {
+		return 0
+	}
+	linkLength := 0
+	s.Find("a").Each(func(i int, a *goquery.Selection) {
+		linkLength += len(normalizeSpace(a.Text()))
+	})
+	return float64(linkLength) / float64(length)
+}
+
+// articleContent collects the top candidate together with siblings that
+// look like part of the article, such as paragraphs split across several
+// containers.
+func articleContent(top *goquery.Selection) *goquery.Selection {
+	parent := top.Parent()
+	if parent.Length() == 0 || top.Is("body") {
+		return top
+	}
+
+	threshold := math.Max(10, topScore(top)*0.2)
+	content := top.Clone()
+	wrapper := goquery.NewDocumentFromNode(&html.Node{Type: html.ElementNode, Data: "div"}).Selection
+	included := false
+
+	parent.Children().Each(func(i int, sibling *goquery.Selection) {
+		if sibling.Get(0) == top.Get(0) {
+			wrapper.AppendSelection(content)
+			return
+		}
+
+		text := normalizeSpace(sibling.Text())
+		density := linkDensity(sibling)
+		keep := topScore(sibling) >= threshold
+		if sibling.Is("p") {
+			keep = keep || (len(text) > 80 && density < 0.25) ||
+				(len(text) > 0 && len(text) <= 80 && density == 0 && strings.HasSuffix(text, "."))
+		}
+		if keep {
+			wrapper.AppendSelection(sibling.Clone())
+			included = true
+		}
+	})
+
+	if !included {
+		return content
+	}
+	return wrapper
+}
+
+// topScore scores an element from its own paragraphs, for comparing the
+// top candidate's siblings against it.
+func topScore(s *goquery.Selection) float64 {
+	score := initialScore(s)
+	s.ChildrenFiltered("p, pre, td, blockquote").Each(func(i int, p *goquery.Selection) {
+		text := normalizeSpace(p.Text())
+		if len(text) >= minParagraphLength {
+			score += 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		}
+	})
+	return score
+}
+
+// cleanContent strips presentational attributes, drops leftover link lists
+// and empty elements, and makes links and images absolute.
+func cleanContent(content *goquery.Selection, base *url.URL) {
+	content.Find("ul, ol, div, section, table").Each(func(i int, s *goquery.Selection) {
+		if s.Find("img").Length() == 0 && linkDensity(s) > 0.5 {
+			s.Remove()
+		}
+	})
+
+	content.Find("p, div, span, section").Each(func(i int, s *goquery.Selection) {
+		if normalizeSpace(s.Text()) == "" && s.Find("img, video, picture").Length() == 0 {
+			s.Remove()
+		}
+	})
+
+	clean := func(s *goquery.Selection) {
+		node := s.Get(0)
+		attrs := node.Attr[:0]
+		for _, attr := range node.Attr {
+			if keptAttributes[attr.Key] {
+				if (attr.Key == "href" || attr.Key == "src") && base != nil {
+					if u, err := base.Parse(strings.TrimSpace(attr.Val)); err == nil {
+						attr.Val = u.String()
+					}
+				}
+				attrs = append(attrs, attr)
+			}
+		}
+		node.Attr = attrs
+	}
+	clean(content)
+	content.Find("*").Each(func(i int, s *goquery.Selection) { clean(s) })
+}
+
+func articleTitle(doc *goquery.Document) string {
+	if title := metaContent(doc, `meta[property="og:title"], meta[name="twitter:title"]`); title != "" {
+		return title
+	}
+	if h1 := doc.Find("h1"); h1.Length() == 1 {
+		return normalizeSpace(h1.Text())
+	}
+	return normalizeSpace(doc.Find("title").First().Text())
+}
+
+func articleByline(doc *goquery.Document) string {
+	if author := metaContent(doc, `meta[name="author"], meta[property="article:author"]`); author != "" && !strings.Contains(author, "://") {
+		return author
+	}
+
+	var byline string
+	doc.Find(`[rel="author"], [itemprop="author"], [class], [id]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		class, _ := s.Attr("class")
+		id, _ := s.Attr("id")
+		rel, _ := s.Attr("rel")
+		itemprop, _ := s.Attr("itemprop")
+		if rel != "author" && !strings.Contains(itemprop, "author") && !bylineClass.MatchString(class+" "+id) {
+			return true
+		}
+		if text := normalizeSpace(s.Text()); text != "" && len(text) < 100 {
+			if len(text) > 3 && strings.EqualFold(text[:3], "by ") {
+				text = strings.TrimSpace(text[3:])
+			}
+			byline = text
+			return false
+		}
+		return true
+	})
+	return byline
+}
+
+func metaContent(doc *goquery.Document, selector string) string {
+	var value string
+	doc.Find(selector).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		value = strings.TrimSpace(s.AttrOr("content", ""))
+		return value == ""
+	})
+	return value
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,73 @@
+package transforms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+type SubPipeNode struct{}
+
+func (n *SubPipeNode) Type() string        { return "sub-pipe" }
+func (n *SubPipeNode) Label() string       { return "Sub-pipe" }
+func (n *SubPipeNode) Description() string { return "Run another pipe on the items and use its output" }
+func (n *SubPipeNode) Category() string    { return "transform" }
+func (n *SubPipeNode) Inputs() int         { return 1 }
+func (n *SubPipeNode) Outputs() int        { return 1 }
+
+func (n *SubPipeNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	pipeID, _ := config["pipe_id"].(string)
+	pipeID = strings.TrimSpace(pipeID)
+
+	input, _ := config["input"].(string)
+	if input == "" {
+		input = nodes.DefaultPipeInput
+	}
+
+	items := []interface{}{}
+	if len(inputs) > 0 {
+		items = inputs[0]
+	}
+
+	result, err := execCtx.RunPipe(ctx, nodes.PipeCall{PipeID: pipeID, Input: input, Items: items})
+	if err != nil {
+		return nil, err
+	}
+
+	execCtx.Log("sub-pipe", "info", fmt.Sprintf("Sent %d items, got %d back", len(items), len(result)))
+	return result, nil
+}
+
+func (n *SubPipeNode) ValidateConfig(config map[string]interface{}) error {
+	pipeID, _ := config["pipe_id"].(string)
+	if strings.TrimSpace(pipeID) == "" {
+		return fmt.Errorf("pipe_id is required")
+	}
+	return nil
+}
+
+func (n *SubPipeNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:        "pipe_id",
+				Label:       "Pipe ID",
+				Type:        "text",
+				Required:    true,
+				Placeholder: "ID from the pipe's editor URL",
+				HelpText:    "Pipe to run. It must be yours or public. Its last node's output becomes this node's output.",
+			},
+			{
+				Name:         "input",
+				Label:        "Input Name",
+				Type:         "text",
+				Required:     false,
+				DefaultValue: nodes.DefaultPipeInput,
+				Placeholder:  nodes.DefaultPipeInput,
+				HelpText:     "Pipe Input node in the other pipe that receives this node's items",
+			},
+		},
+	}
+}
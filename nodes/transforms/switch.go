@@ -0,0 +1,197 @@
+package transforms
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+const switchDefaultPort = "default"
+
+// filterOperators are the operators understood by matchesFilter
+var filterOperators = map[string]bool{
+	"contains":   true,
+	"equals":     true,
+	"not-equals": true,
+	"regex":      true,
+}
+
+type SwitchNode struct{}
+
+type switchRule struct {
+	port     string
+	field    string
+	operator string
+	value    string
+}
+
+func (n *SwitchNode) Type() string  { return "switch" }
+func (n *SwitchNode) Label() string { return "Switch" }
+func (n *SwitchNode) Description() string {
+	return "Route items to the branch of the first matching rule"
+}
+func (n *SwitchNode) Category() string { return "transform" }
+func (n *SwitchNode) Inputs() int      { return 1 }
+func (n *SwitchNode) Outputs() int     { return 1 }
+
+// OutputPorts has one port per rule, in rule order, followed by the default
+// port for items no rule matched.
+func (n *SwitchNode) OutputPorts(config map[string]interface{}) []string {
+	rules, _ := parseSwitchRules(config)
+
+	ports := make([]string, 0, len(rules)+1)
+	for _, rule := range rules {
+		ports = append(ports, rule.port)
+	}
+	return append(ports, switchDefaultPort)
+}
+
+func (n *SwitchNode) Execute(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) ([]interface{}, error) {
+	ports, err := n.ExecutePorts(ctx, config, inputs, execCtx)
+	if err != nil {
+		return nil, err
+	}
+	return ports[n.OutputPorts(config)[0]], nil
+}
+
+func (n *SwitchNode) ExecutePorts(ctx context.Context, config map[string]interface{}, inputs [][]interface{}, execCtx *nodes.Context) (map[string][]interface{}, error) {
+	rules, err := parseSwitchRules(config)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string][]interface{}{switchDefaultPort: {}}
+	for _, rule := range rules {
+		result[rule.port] = []interface{}{}
+	}
+
+	if len(inputs) == 0 {
+		return result, nil
+	}
+
+	for _, item := range inputs[0] {
+		port := switchDefaultPort
+		for _, rule := range rules {
+			if matchesFilter(item, rule.field, rule.operator, rule.value) {
+				port = rule.port
+				break
+			}
+		}
+		result[port] = append(result[port], item)
+	}
+
+	var counts []string
+	for _, rule := range rules {
+		counts = append(counts, fmt.Sprintf("%s=%d", rule.port, len(result[rule.port])))
+	}
+	counts = append(counts, fmt.Sprintf("%s=%d", switchDefaultPort, len(result[switchDefaultPort])))
+	execCtx.Log("switch", "info", fmt.Sprintf("Routed %d items: %s", len(inputs[0]), strings.Join(counts, ", ")))
+
+	return result, nil
+}
+
+// parseSwitchRules reads one rule per line in the form
+// "[name:] field operator value". Unnamed rules get ports rule-1, rule-2, ...
+// by position; blank lines and lines starting with # are skipped.
+func parseSwitchRules(config map[string]interface{}) ([]switchRule, error) {
+	text, _ := config["rules"].(string)
+
+	var rules []switchRule
+	seen := map[string]bool{switchDefaultPort: true}
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := switchRule{port: fmt.Sprintf("rule-%d", len(rules)+1)}
+
+		parts := strings.SplitN(line, " ", 2)
+		if strings.HasSuffix(parts[0], ":") {
+			rule.port = strings.TrimSuffix(parts[0], ":")
+			if len(parts) < 2 {
+				return nil, fmt.Errorf("line %d: missing condition", i+1)
+			}
+			line = strings.TrimSpace(parts[1])
+		}
+
+		parts = strings.SplitN(line, " ", 3)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("line %d: expected \"field operator value\"", i+1)
+		}
+		rule.field, rule.operator = parts[0], parts[1]
+		if len(parts) == 3 {
+			rule.value = parts[2]
+		}
+
+		if !filterOperators[rule.operator] {
+			return nil, fmt.Errorf("line %d: unknown operator %q", i+1, rule.operator)
+		}
+		if rule.operator == "regex" {
+			if _, err := regexp.Compile(rule.value); err != nil {
+				return nil, fmt.Errorf("line %d: invalid regex: %w", i+1, err)
+			}
+		}
+		if rule.port == "" || seen[rule.port] {
+			return nil, fmt.Errorf("line %d: duplicate or reserved branch name %q", i+1, rule.port)
+		}
+		seen[rule.port] = true
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (n *SwitchNode) ValidateConfig(config map[string]interface{}) error {
+	_, err := parseSwitchRules(config)
+	return err
+}
+
+func (n *SwitchNode) GetConfigSchema() *nodes.ConfigSchema {
+	return &nodes.ConfigSchema{
+		Fields: []nodes.ConfigField{
+			{
+				Name:        "rules",
+				Label:       "Rules",
+				Type:        "textarea",
+				Required:    true,
+				Placeholder: "golang: title contains go\ncategory equals news",
+				HelpText:    "One rule per line: [branch:] field operator value. Operators: contains, equals, not-equals, regex. Fields are compared as text, and only contains ignores case. Each item goes to the first matching branch, or to \"default\".",
+			},
+		},
+	}
+}
+
+// matchesFilter is the Filter's original single-condition match, kept for
+// Switch rules on purpose: the field is compared as its %v text, so a
+// missing field is "<nil>", contains ignores case and regex doesn't.
+// Moving Switch to the condition language would change which branch
+// existing rules route items to.
+func matchesFilter(item interface{}, field, operator, value string) bool {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	fieldValue := getNestedValue(itemMap, field)
+	fieldStr := fmt.Sprintf("%v", fieldValue)
+
+	switch operator {
+	case "contains":
+		return strings.Contains(strings.ToLower(fieldStr), strings.ToLower(value))
+	case "equals":
+		return fieldStr == value
+	case "not-equals":
+		return fieldStr != value
+	case "regex":
+		matched, _ := regexp.MatchString(value, fieldStr)
+		return matched
+	default:
+		return true
+	}
+}
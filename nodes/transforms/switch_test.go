@@ -0,0 +1,42 @@
+package transforms
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kierank/pipes/nodes"
+)
+
+func TestSwitchRoutes(t *testing.T) {
+	config := map[string]interface{}{"rules": "# first match wins\ngo: title contains GO\nmissing: author equals <nil>\nbig: views regex ^1e\\+06$\nnews: category equals News"}
+	items := []interface{}{
+		map[string]interface{}{"title": "Learning Go", "author": "a", "category": "News"},
+		map[string]interface{}{"title": "Rust"},
+		map[string]interface{}{"title": "Zig", "author": "b", "views": float64(1000000)},
+		map[string]interface{}{"title": "Odin", "author": "c", "category": "news"},
+		"not an object",
+	}
+
+	ports, err := (&SwitchNode{}).ExecutePorts(context.Background(), config, [][]interface{}{items}, &nodes.Context{Preview: &nodes.Preview{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rules keep the legacy text comparison: a missing field is "<nil>",
+	// numbers print as %v does, and only contains ignores case
+	want := map[string][]interface{}{
+		"go":              {items[0]},
+		"missing":         {items[1]},
+		"big":             {items[2]},
+		"news":            {},
+		switchDefaultPort: {items[3], items[4]},
+	}
+	if !reflect.DeepEqual(ports, want) {
+		t.Errorf("got %v, want %v", ports, want)
+	}
+
+	if got := (&SwitchNode{}).OutputPorts(config); !reflect.DeepEqual(got, []string{"go", "missing", "big", "news", switchDefaultPort}) {
+		t.Errorf("OutputPorts = %v", got)
+	}
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SourceCache is a cached upstream response for a source node. ExpiresAt is
+// when the data stops being fresh; after that it is revalidated with ETag
+// and LastModified.
+type SourceCache struct {
+	ID           string
+	PipeID       string
+	NodeID       string
+	CacheKey     string
+	Data         string
+	ETag         string
+	LastModified string
+	ExpiresAt    int64
+	CreatedAt    int64
+}
+
+func (db *DB) GetSourceCache(pipeID, nodeID, cacheKey string) (*SourceCache, error) {
+	entry := &SourceCache{}
+	var etag, lastModified sql.NullString
+
+	err := db.QueryRow(`
+		SELECT id, pipe_id, node_id, cache_key, data, etag, last_modified, expires_at, created_at
+		FROM source_cache
+		WHERE pipe_id = ? AND node_id = ? AND cache_key = ?
+	`, pipeID, nodeID, cacheKey).Scan(&entry.ID, &entry.PipeID, &entry.NodeID, &entry.CacheKey, &entry.Data, &etag, &lastModified, &entry.ExpiresAt, &entry.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get source cache: %w", err)
+	}
+
+	entry.ETag = etag.String
+	entry.LastModified = lastModified.String
+
+	return entry, nil
+}
+
+// SaveSourceCache creates or replaces the cache entry for a node and key.
+func (db *DB) SaveSourceCache(entry *SourceCache) error {
+	now := time.Now().Unix()
+
+	_, err := db.Exec(`
+		INSERT INTO source_cache (id, pipe_id, node_id, cache_key, data, etag, last_modified, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(pipe_id, node_id, cache_key) DO UPDATE SET
+			data = excluded.data,
+			etag = excluded.etag,
+			last_modified = excluded.last_modified,
+			expires_at = excluded.expires_at,
+			created_at = excluded.created_at
+	`, uuid.New().String(), entry.PipeID, entry.NodeID, entry.CacheKey, entry.Data, entry.ETag, entry.LastModified, entry.ExpiresAt, now)
+
+	if err != nil {
+		return fmt.Errorf("save source cache: %w", err)
+	}
+
+	return nil
+}
+
+// RefreshSourceCache extends an entry's freshness after a 304 response,
+// keeping its data.
+func (db *DB) RefreshSourceCache(id, etag, lastModified string, expiresAt int64) error {
+	_, err := db.Exec(`
+		UPDATE source_cache
+		SET etag = ?, last_modified = ?, expires_at = ?
+		WHERE id = ?
+	`, etag, lastModified, expiresAt, id)
+
+	if err != nil {
+		return fmt.Errorf("refresh source cache: %w", err)
+	}
+
+	return nil
+}
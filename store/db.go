@@ -9,10 +9,13 @@ import (
 
 type DB struct {
 	*sql.DB
+
+	logStorage LogStorage
 }
 
 func New(path string) (*DB, error) {
-	db, err := sql.Open("sqlite3", path)
+	// Wait on locks instead of failing so concurrent node logs don't collide
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000")
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
@@ -99,6 +102,7 @@ func (db *DB) initSchema() error {
 		next_run_at INTEGER NOT NULL,
 		last_run_at INTEGER,
 		enabled INTEGER NOT NULL DEFAULT 1,
+		timezone TEXT NOT NULL DEFAULT '',
 		created_at INTEGER NOT NULL,
 		updated_at INTEGER NOT NULL
 	);
@@ -116,7 +120,8 @@ func (db *DB) initSchema() error {
 		duration_ms INTEGER,
 		items_processed INTEGER,
 		error_message TEXT,
-		metadata TEXT
+		metadata TEXT,
+		parent_execution_id TEXT REFERENCES pipe_executions(id) ON DELETE SET NULL
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_executions_pipe_id ON pipe_executions(pipe_id);
@@ -149,7 +154,53 @@ func (db *DB) initSchema() error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_cache_pipe_node ON source_cache(pipe_id, node_id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_cache_key ON source_cache(pipe_id, node_id, cache_key);
 	CREATE INDEX IF NOT EXISTS idx_cache_expires ON source_cache(expires_at);
+
+	-- Node state (values kept across runs)
+	CREATE TABLE IF NOT EXISTS node_state (
+		pipe_id TEXT NOT NULL REFERENCES pipes(id) ON DELETE CASCADE,
+		node_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (pipe_id, node_id, key)
+	);
+
+	-- Seen items (for emitting only new items)
+	CREATE TABLE IF NOT EXISTS seen_items (
+		pipe_id TEXT NOT NULL REFERENCES pipes(id) ON DELETE CASCADE,
+		node_id TEXT NOT NULL,
+		item_key TEXT NOT NULL,
+		first_seen_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (pipe_id, node_id, item_key)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_seen_expires ON seen_items(expires_at);
+
+	-- Webhook triggers (one inbound URL per pipe)
+	CREATE TABLE IF NOT EXISTS pipe_webhooks (
+		id TEXT PRIMARY KEY,
+		pipe_id TEXT NOT NULL UNIQUE REFERENCES pipes(id) ON DELETE CASCADE,
+		token TEXT NOT NULL,
+		secret TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		last_triggered_at INTEGER
+	);
+
+	-- Pipe revisions (every saved version of a pipe)
+	CREATE TABLE IF NOT EXISTS pipe_revisions (
+		id TEXT PRIMARY KEY,
+		pipe_id TEXT NOT NULL REFERENCES pipes(id) ON DELETE CASCADE,
+		revision INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		config TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_revisions_pipe ON pipe_revisions(pipe_id, revision);
 	`
 
 	_, err := db.Exec(schema)
@@ -157,5 +208,75 @@ func (db *DB) initSchema() error {
 		return fmt.Errorf("execute schema: %w", err)
 	}
 
+	return db.migrateSchema()
+}
+
+// migrateSchema adds columns introduced after a table was first created.
+// CREATE TABLE IF NOT EXISTS leaves existing databases untouched, so new
+// columns are added here instead.
+func (db *DB) migrateSchema() error {
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"scheduled_jobs", "timezone", "TEXT NOT NULL DEFAULT ''"},
+		{"pipe_executions", "parent_execution_id", "TEXT REFERENCES pipe_executions(id) ON DELETE SET NULL"},
+	}
+
+	for _, c := range columns {
+		if err := db.addColumnIfMissing(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	// Indexes on added columns can only be created once the column exists
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_executions_parent ON pipe_executions(parent_execution_id)`); err != nil {
+		return fmt.Errorf("create parent execution index: %w", err)
+	}
+
+	// Pipes saved before revisions were kept start from their current state
+	_, err := db.Exec(`
+		INSERT INTO pipe_revisions (id, pipe_id, revision, name, description, config, created_at)
+		SELECT lower(hex(randomblob(16))), id, 1, name, COALESCE(description, ''), config, updated_at
+		FROM pipes
+		WHERE NOT EXISTS (SELECT 1 FROM pipe_revisions WHERE pipe_id = pipes.id)
+	`)
+	if err != nil {
+		return fmt.Errorf("record initial revisions: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) addColumnIfMissing(table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("table info %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return fmt.Errorf("scan table info %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	rows.Close()
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("add column %s.%s: %w", table, column, err)
+	}
+
 	return nil
 }
@@ -19,6 +19,10 @@ type PipeExecution struct {
 	ItemsProcessed *int    `json:"items_processed,omitempty"`
 	ErrorMessage   *string `json:"error_message,omitempty"`
 	Metadata       *string `json:"metadata,omitempty"`
+
+	// ParentExecutionID is set for runs started by a sub-pipe node in
+	// another pipe's execution.
+	ParentExecutionID *string `json:"parent_execution_id,omitempty"`
 }
 
 type ExecutionLog struct {
@@ -31,11 +35,18 @@ type ExecutionLog struct {
 	Metadata    *string `json:"metadata,omitempty"`
 }
 
-func (db *DB) CreateExecution(id, pipeID, triggerType string, startedAt int64) error {
+// CreateExecution records a running execution. parentExecutionID links a
+// sub-pipe run to the execution that called it and is empty otherwise.
+func (db *DB) CreateExecution(id, pipeID, triggerType, parentExecutionID string, startedAt int64) error {
+	var parent sql.NullString
+	if parentExecutionID != "" {
+		parent = sql.NullString{String: parentExecutionID, Valid: true}
+	}
+
 	_, err := db.Exec(`
-		INSERT INTO pipe_executions (id, pipe_id, status, trigger_type, started_at)
-		VALUES (?, ?, ?, ?, ?)
-	`, id, pipeID, "running", triggerType, startedAt)
+		INSERT INTO pipe_executions (id, pipe_id, status, trigger_type, started_at, parent_execution_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, id, pipeID, "running", triggerType, startedAt, parent)
 
 	if err != nil {
 		return fmt.Errorf("insert execution: %w", err)
@@ -72,17 +83,63 @@ func (db *DB) UpdateExecutionFailed(id string, completedAt, durationMs int64, er
 	return nil
 }
 
+func (db *DB) UpdateExecutionCancelled(id string, completedAt, durationMs int64, reason string) error {
+	_, err := db.Exec(`
+		UPDATE pipe_executions
+		SET status = ?, completed_at = ?, duration_ms = ?, error_message = ?
+		WHERE id = ?
+	`, "cancelled", completedAt, durationMs, reason, id)
+
+	if err != nil {
+		return fmt.Errorf("update execution: %w", err)
+	}
+
+	return nil
+}
+
+// CancelOrphanedExecutions marks executions still "running" as cancelled.
+// It is called at startup, when no execution can actually be running.
+func (db *DB) CancelOrphanedExecutions(reason string) (int64, error) {
+	now := time.Now().Unix()
+
+	result, err := db.Exec(`
+		UPDATE pipe_executions
+		SET status = ?, completed_at = ?, duration_ms = (? - started_at) * 1000, error_message = ?
+		WHERE status = ?
+	`, "cancelled", now, now, reason, "running")
+
+	if err != nil {
+		return 0, fmt.Errorf("cancel orphaned executions: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (db *DB) UpdateExecutionMetadata(id, metadata string) error {
+	_, err := db.Exec(`
+		UPDATE pipe_executions
+		SET metadata = ?
+		WHERE id = ?
+	`, metadata, id)
+
+	if err != nil {
+		return fmt.Errorf("update execution metadata: %w", err)
+	}
+
+	return nil
+}
+
 func (db *DB) GetExecution(id string) (*PipeExecution, error) {
 	exec := &PipeExecution{}
 	var completedAt, durationMs sql.NullInt64
 	var itemsProcessed sql.NullInt64
-	var errorMessage, metadata sql.NullString
+	var errorMessage, metadata, parentExecutionID sql.NullString
 
 	err := db.QueryRow(`
-		SELECT id, pipe_id, status, trigger_type, started_at, completed_at, duration_ms, items_processed, error_message, metadata
+		SELECT id, pipe_id, status, trigger_type, started_at, completed_at, duration_ms, items_processed, error_message, metadata, parent_execution_id
 		FROM pipe_executions
 		WHERE id = ?
-	`, id).Scan(&exec.ID, &exec.PipeID, &exec.Status, &exec.TriggerType, &exec.StartedAt, &completedAt, &durationMs, &itemsProcessed, &errorMessage, &metadata)
+	`, id).Scan(&exec.ID, &exec.PipeID, &exec.Status, &exec.TriggerType, &exec.StartedAt, &completedAt, &durationMs, &itemsProcessed, &errorMessage, &metadata, &parentExecutionID)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -115,12 +172,16 @@ func (db *DB) GetExecution(id string) (*PipeExecution, error) {
 		exec.Metadata = &metadata.String
 	}
 
+	if parentExecutionID.Valid {
+		exec.ParentExecutionID = &parentExecutionID.String
+	}
+
 	return exec, nil
 }
 
 func (db *DB) GetPipeExecutions(pipeID string, limit int) ([]*PipeExecution, error) {
 	rows, err := db.Query(`
-		SELECT id, pipe_id, status, trigger_type, started_at, completed_at, duration_ms, items_processed, error_message, metadata
+		SELECT id, pipe_id, status, trigger_type, started_at, completed_at, duration_ms, items_processed, error_message, metadata, parent_execution_id
 		FROM pipe_executions
 		WHERE pipe_id = ?
 		ORDER BY started_at DESC
@@ -137,9 +198,9 @@ func (db *DB) GetPipeExecutions(pipeID string, limit int) ([]*PipeExecution, err
 		exec := &PipeExecution{}
 		var completedAt, durationMs sql.NullInt64
 		var itemsProcessed sql.NullInt64
-		var errorMessage, metadata sql.NullString
+		var errorMessage, metadata, parentExecutionID sql.NullString
 
-		if err := rows.Scan(&exec.ID, &exec.PipeID, &exec.Status, &exec.TriggerType, &exec.StartedAt, &completedAt, &durationMs, &itemsProcessed, &errorMessage, &metadata); err != nil {
+		if err := rows.Scan(&exec.ID, &exec.PipeID, &exec.Status, &exec.TriggerType, &exec.StartedAt, &completedAt, &durationMs, &itemsProcessed, &errorMessage, &metadata, &parentExecutionID); err != nil {
 			return nil, fmt.Errorf("scan execution: %w", err)
 		}
 
@@ -166,6 +227,10 @@ func (db *DB) GetPipeExecutions(pipeID string, limit int) ([]*PipeExecution, err
 			exec.Metadata = &metadata.String
 		}
 
+		if parentExecutionID.Valid {
+			exec.ParentExecutionID = &parentExecutionID.String
+		}
+
 		executions = append(executions, exec)
 	}
 
@@ -179,7 +244,7 @@ func (db *DB) LogExecution(executionID, nodeID, level, message string) error {
 	_, err := db.Exec(`
 		INSERT INTO execution_logs (id, execution_id, node_id, level, message, timestamp)
 		VALUES (?, ?, ?, ?, ?, ?)
-	`, logID, executionID, nodeID, level, message, timestamp)
+	`, logID, executionID, nodeID, level, db.truncateMessage(message), timestamp)
 
 	if err != nil {
 		return fmt.Errorf("insert log: %w", err)
@@ -195,7 +260,7 @@ func (db *DB) LogExecutionWithData(executionID, nodeID, level, message, data str
 	_, err := db.Exec(`
 		INSERT INTO execution_logs (id, execution_id, node_id, level, message, timestamp, metadata)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, logID, executionID, nodeID, level, message, timestamp, data)
+	`, logID, executionID, nodeID, level, db.truncateMessage(message), timestamp, db.encodePayload(data))
 
 	if err != nil {
 		return fmt.Errorf("insert log: %w", err)
@@ -204,6 +269,38 @@ func (db *DB) LogExecutionWithData(executionID, nodeID, level, message, data str
 	return nil
 }
 
+// GetExecutionOutputs returns the output each node of an execution logged,
+// as JSON keyed by node ID. If a node logged output more than once, as it
+// can when the run was retried, the last one is returned.
+func (db *DB) GetExecutionOutputs(executionID string) (map[string]string, error) {
+	rows, err := db.Query(`
+		SELECT node_id, metadata
+		FROM execution_logs
+		WHERE execution_id = ? AND level = 'data' AND metadata IS NOT NULL
+		ORDER BY timestamp ASC, rowid ASC
+	`, executionID)
+
+	if err != nil {
+		return nil, fmt.Errorf("query outputs: %w", err)
+	}
+	defer rows.Close()
+
+	outputs := make(map[string]string)
+	for rows.Next() {
+		var nodeID, stored string
+		if err := rows.Scan(&nodeID, &stored); err != nil {
+			return nil, fmt.Errorf("scan output: %w", err)
+		}
+		data, err := decodePayload(stored)
+		if err != nil {
+			return nil, err
+		}
+		outputs[nodeID] = data
+	}
+
+	return outputs, nil
+}
+
 func (db *DB) GetExecutionLogs(executionID string) ([]*ExecutionLog, error) {
 	rows, err := db.Query(`
 		SELECT id, execution_id, node_id, level, message, timestamp, metadata
@@ -227,7 +324,11 @@ func (db *DB) GetExecutionLogs(executionID string) ([]*ExecutionLog, error) {
 		}
 
 		if metadata.Valid {
-			log.Metadata = &metadata.String
+			data, err := decodePayload(metadata.String)
+			if err != nil {
+				return nil, err
+			}
+			log.Metadata = &data
 		}
 
 		logs = append(logs, log)
@@ -29,14 +29,15 @@ type PipeOutput struct {
 }
 
 type ScheduledJob struct {
-	ID             string
-	PipeID         string
-	CronExpression string
-	NextRunAt      int64
-	LastRunAt      *int64
-	Enabled        bool
-	CreatedAt      int64
-	UpdatedAt      int64
+	ID             string `json:"id"`
+	PipeID         string `json:"pipe_id"`
+	CronExpression string `json:"cron_expression"`
+	Timezone       string `json:"timezone"`
+	NextRunAt      int64  `json:"next_run_at"`
+	LastRunAt      *int64 `json:"last_run_at,omitempty"`
+	Enabled        bool   `json:"enabled"`
+	CreatedAt      int64  `json:"created_at"`
+	UpdatedAt      int64  `json:"updated_at"`
 }
 
 func (db *DB) CreatePipe(userID, name, description, config string, isPublic bool) (*Pipe, error) {
@@ -52,7 +53,13 @@ func (db *DB) CreatePipe(userID, name, description, config string, isPublic bool
 		UpdatedAt:   now,
 	}
 
-	_, err := db.Exec(`
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		INSERT INTO pipes (id, user_id, name, description, config, is_public, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`, pipe.ID, pipe.UserID, pipe.Name, pipe.Description, pipe.Config, btoi(pipe.IsPublic), pipe.CreatedAt, pipe.UpdatedAt)
@@ -61,6 +68,14 @@ func (db *DB) CreatePipe(userID, name, description, config string, isPublic bool
 		return nil, fmt.Errorf("insert pipe: %w", err)
 	}
 
+	if err := addPipeRevision(tx, pipe); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit pipe: %w", err)
+	}
+
 	return pipe, nil
 }
 
@@ -115,10 +130,34 @@ func (db *DB) GetUserPipes(userID string) ([]*Pipe, error) {
 	return pipes, nil
 }
 
+// UpdatePipe saves the pipe and records it as a new revision if its name,
+// description or config changed.
 func (db *DB) UpdatePipe(pipe *Pipe) error {
+	return db.updatePipe(pipe, nil)
+}
+
+// UpdatePipeSchedule saves the pipe as UpdatePipe does, and in the same
+// transaction sets its scheduled job to job, or deletes the job if job is
+// nil, so a failure leaves both as they were.
+func (db *DB) UpdatePipeSchedule(pipe *Pipe, job *ScheduledJob) error {
+	return db.updatePipe(pipe, func(tx *sql.Tx) error {
+		if job == nil {
+			return deleteScheduledJobByPipe(tx, pipe.ID)
+		}
+		return upsertScheduledJob(tx, pipe.ID, job.CronExpression, job.Timezone, job.NextRunAt, job.Enabled)
+	})
+}
+
+func (db *DB) updatePipe(pipe *Pipe, also func(tx *sql.Tx) error) error {
 	pipe.UpdatedAt = time.Now().Unix()
 
-	_, err := db.Exec(`
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
 		UPDATE pipes
 		SET name = ?, description = ?, config = ?, is_public = ?, updated_at = ?
 		WHERE id = ?
@@ -128,6 +167,20 @@ func (db *DB) UpdatePipe(pipe *Pipe) error {
 		return fmt.Errorf("update pipe: %w", err)
 	}
 
+	if err := addPipeRevision(tx, pipe); err != nil {
+		return err
+	}
+
+	if also != nil {
+		if err := also(tx); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit pipe: %w", err)
+	}
+
 	return nil
 }
 
@@ -179,12 +232,13 @@ func (db *DB) GetPipeOutput(pipeID, format string) (*PipeOutput, error) {
 	return output, nil
 }
 
-func (db *DB) CreateScheduledJob(pipeID, cronExpression string, nextRunAt int64) (*ScheduledJob, error) {
+func (db *DB) CreateScheduledJob(pipeID, cronExpression, timezone string, nextRunAt int64) (*ScheduledJob, error) {
 	now := time.Now().Unix()
 	job := &ScheduledJob{
 		ID:             uuid.New().String(),
 		PipeID:         pipeID,
 		CronExpression: cronExpression,
+		Timezone:       timezone,
 		NextRunAt:      nextRunAt,
 		Enabled:        true,
 		CreatedAt:      now,
@@ -192,9 +246,9 @@ func (db *DB) CreateScheduledJob(pipeID, cronExpression string, nextRunAt int64)
 	}
 
 	_, err := db.Exec(`
-		INSERT INTO scheduled_jobs (id, pipe_id, cron_expression, next_run_at, enabled, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, job.ID, job.PipeID, job.CronExpression, job.NextRunAt, btoi(job.Enabled), job.CreatedAt, job.UpdatedAt)
+		INSERT INTO scheduled_jobs (id, pipe_id, cron_expression, timezone, next_run_at, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, job.ID, job.PipeID, job.CronExpression, job.Timezone, job.NextRunAt, btoi(job.Enabled), job.CreatedAt, job.UpdatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("insert scheduled job: %w", err)
@@ -203,9 +257,81 @@ func (db *DB) CreateScheduledJob(pipeID, cronExpression string, nextRunAt int64)
 	return job, nil
 }
 
+// UpsertScheduledJob creates or updates the scheduled job for a pipe. Each
+// pipe has at most one job; last_run_at is preserved on update.
+func (db *DB) UpsertScheduledJob(pipeID, cronExpression, timezone string, nextRunAt int64, enabled bool) error {
+	return upsertScheduledJob(db, pipeID, cronExpression, timezone, nextRunAt, enabled)
+}
+
+// execer runs a statement on the database or in a transaction.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func upsertScheduledJob(db execer, pipeID, cronExpression, timezone string, nextRunAt int64, enabled bool) error {
+	now := time.Now().Unix()
+
+	_, err := db.Exec(`
+		INSERT INTO scheduled_jobs (id, pipe_id, cron_expression, timezone, next_run_at, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(pipe_id) DO UPDATE SET
+			cron_expression = excluded.cron_expression,
+			timezone = excluded.timezone,
+			next_run_at = excluded.next_run_at,
+			enabled = excluded.enabled,
+			updated_at = excluded.updated_at
+	`, uuid.New().String(), pipeID, cronExpression, timezone, nextRunAt, btoi(enabled), now, now)
+
+	if err != nil {
+		return fmt.Errorf("upsert scheduled job: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) GetScheduledJobByPipe(pipeID string) (*ScheduledJob, error) {
+	job := &ScheduledJob{}
+	var enabled int
+	var lastRunAt sql.NullInt64
+
+	err := db.QueryRow(`
+		SELECT id, pipe_id, cron_expression, timezone, next_run_at, last_run_at, enabled, created_at, updated_at
+		FROM scheduled_jobs
+		WHERE pipe_id = ?
+	`, pipeID).Scan(&job.ID, &job.PipeID, &job.CronExpression, &job.Timezone, &job.NextRunAt, &lastRunAt, &enabled, &job.CreatedAt, &job.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query scheduled job: %w", err)
+	}
+
+	job.Enabled = enabled == 1
+	if lastRunAt.Valid {
+		val := lastRunAt.Int64
+		job.LastRunAt = &val
+	}
+
+	return job, nil
+}
+
+func (db *DB) DeleteScheduledJobByPipe(pipeID string) error {
+	return deleteScheduledJobByPipe(db, pipeID)
+}
+
+func deleteScheduledJobByPipe(db execer, pipeID string) error {
+	_, err := db.Exec("DELETE FROM scheduled_jobs WHERE pipe_id = ?", pipeID)
+	if err != nil {
+		return fmt.Errorf("delete scheduled job: %w", err)
+	}
+	return nil
+}
+
 func (db *DB) GetDueJobs(now int64) ([]*ScheduledJob, error) {
 	rows, err := db.Query(`
-		SELECT id, pipe_id, cron_expression, next_run_at, last_run_at, enabled, created_at, updated_at
+		SELECT id, pipe_id, cron_expression, timezone, next_run_at, last_run_at, enabled, created_at, updated_at
 		FROM scheduled_jobs
 		WHERE enabled = 1 AND next_run_at <= ?
 	`, now)
@@ -221,7 +347,7 @@ func (db *DB) GetDueJobs(now int64) ([]*ScheduledJob, error) {
 		var enabled int
 		var lastRunAt sql.NullInt64
 
-		if err := rows.Scan(&job.ID, &job.PipeID, &job.CronExpression, &job.NextRunAt, &lastRunAt, &enabled, &job.CreatedAt, &job.UpdatedAt); err != nil {
+		if err := rows.Scan(&job.ID, &job.PipeID, &job.CronExpression, &job.Timezone, &job.NextRunAt, &lastRunAt, &enabled, &job.CreatedAt, &job.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan job: %w", err)
 		}
 
@@ -253,6 +379,22 @@ func (db *DB) UpdateJobAfterRun(id string, lastRunAt, nextRunAt int64) error {
 	return nil
 }
 
+func (db *DB) DisableScheduledJob(id string) error {
+	now := time.Now().Unix()
+
+	_, err := db.Exec(`
+		UPDATE scheduled_jobs
+		SET enabled = 0, updated_at = ?
+		WHERE id = ?
+	`, now, id)
+
+	if err != nil {
+		return fmt.Errorf("disable job: %w", err)
+	}
+
+	return nil
+}
+
 func btoi(b bool) int {
 	if b {
 		return 1
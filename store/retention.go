@@ -0,0 +1,246 @@
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LogStorage controls how execution logs are stored.
+type LogStorage struct {
+	// MaxPayload caps the bytes stored for a log row's message and data; 0
+	// stores them in full. Longer messages are cut short, and data that
+	// would be longer is replaced with a note of its size (see
+	// TruncatedPayload).
+	MaxPayload int
+
+	// Compress gzips log data before storing it. Data is decompressed
+	// when read, so callers don't need to know how it was stored.
+	Compress bool
+}
+
+// SetLogStorage changes how execution logs written from now on are stored.
+// It should be called before the database is in use.
+func (db *DB) SetLogStorage(s LogStorage) {
+	db.logStorage = s
+}
+
+// gzipPrefix marks compressed log data. JSON can't start with it.
+const gzipPrefix = "gzip:"
+
+// encodePayload prepares log data for storage.
+func (db *DB) encodePayload(data string) string {
+	stored := data
+	if db.logStorage.Compress {
+		if compressed, err := compress(data); err == nil && len(compressed) < len(data) {
+			stored = compressed
+		}
+	}
+
+	if max := db.logStorage.MaxPayload; max > 0 && len(stored) > max {
+		note, _ := json.Marshal(truncatedPayload{Truncated: true, Bytes: len(data)})
+		return string(note)
+	}
+	return stored
+}
+
+// decodePayload returns stored log data as it was logged.
+func decodePayload(stored string) (string, error) {
+	if !strings.HasPrefix(stored, gzipPrefix) {
+		return stored, nil
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(stored[len(gzipPrefix):])
+	if err != nil {
+		return "", fmt.Errorf("decode log data: %w", err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", fmt.Errorf("decompress log data: %w", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("decompress log data: %w", err)
+	}
+	return string(data), nil
+}
+
+func compress(data string) (string, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return gzipPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// truncateMessage cuts a log message down to the maximum payload size.
+func (db *DB) truncateMessage(message string) string {
+	max := db.logStorage.MaxPayload
+	if max <= 0 || len(message) <= max {
+		return message
+	}
+
+	cut := max
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + fmt.Sprintf("… (%d bytes truncated)", len(message)-cut)
+}
+
+// truncatedPayload is stored instead of log data over the size limit.
+type truncatedPayload struct {
+	Truncated bool `json:"truncated"`
+	Bytes     int  `json:"bytes"`
+}
+
+// TruncatedPayload reports whether log data is the note stored in place of
+// data over the size limit, and if so how many bytes the data had.
+func TruncatedPayload(data string) (int, bool) {
+	if !strings.HasPrefix(data, `{"truncated":true`) {
+		return 0, false
+	}
+	var note truncatedPayload
+	if err := json.Unmarshal([]byte(data), &note); err != nil {
+		return 0, false
+	}
+	return note.Bytes, note.Truncated
+}
+
+// PruneOptions choose which executions PruneExecutions deletes. Running
+// executions are always kept.
+type PruneOptions struct {
+	// KeepPerPipe is how many of each pipe's newest executions to keep; 0
+	// keeps them all.
+	KeepPerPipe int
+
+	// MaxAge deletes executions that started longer ago; 0 keeps them
+	// regardless of age.
+	MaxAge time.Duration
+
+	// DryRun counts what would be deleted without deleting it
+	DryRun bool
+}
+
+// PruneResult counts the executions and log rows deleted.
+type PruneResult struct {
+	Executions int `json:"executions"`
+	Logs       int `json:"logs"`
+}
+
+// pruneBatch is how many executions are deleted per statement, to stay
+// well within SQLite's limit on query parameters.
+const pruneBatch = 500
+
+// PruneExecutions deletes old executions and their logs.
+func (db *DB) PruneExecutions(opts PruneOptions) (*PruneResult, error) {
+	result := &PruneResult{}
+	if opts.KeepPerPipe <= 0 && opts.MaxAge <= 0 {
+		return result, nil
+	}
+
+	var cutoff int64
+	if opts.MaxAge > 0 {
+		cutoff = time.Now().Add(-opts.MaxAge).Unix()
+	}
+
+	rows, err := db.Query(`
+		SELECT id FROM (
+			SELECT id, status, started_at,
+				ROW_NUMBER() OVER (PARTITION BY pipe_id ORDER BY started_at DESC, rowid DESC) AS n
+			FROM pipe_executions
+		)
+		WHERE status != 'running' AND ((? > 0 AND n > ?) OR (? > 0 AND started_at < ?))
+	`, opts.KeepPerPipe, opts.KeepPerPipe, cutoff, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("query old executions: %w", err)
+	}
+
+	var ids []interface{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan execution: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for len(ids) > 0 {
+		batch := ids[:min(len(ids), pruneBatch)]
+		ids = ids[len(batch):]
+
+		n, logs, err := db.deleteExecutions(batch, opts.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		result.Executions += n
+		result.Logs += logs
+	}
+
+	return result, nil
+}
+
+// deleteExecutions deletes a batch of executions and their logs, or only
+// counts them for a dry run.
+func (db *DB) deleteExecutions(ids []interface{}, dryRun bool) (int, int, error) {
+	in := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+
+	if dryRun {
+		var logs int
+		err := db.QueryRow(`SELECT COUNT(*) FROM execution_logs WHERE execution_id IN (`+in+`)`, ids...).Scan(&logs)
+		if err != nil {
+			return 0, 0, fmt.Errorf("count logs: %w", err)
+		}
+		return len(ids), logs, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Delete logs explicitly rather than relying on the cascade, which
+	// needs foreign keys enabled on the connection
+	res, err := tx.Exec(`DELETE FROM execution_logs WHERE execution_id IN (`+in+`)`, ids...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("delete logs: %w", err)
+	}
+	logs, _ := res.RowsAffected()
+
+	if _, err := tx.Exec(`UPDATE pipe_executions SET parent_execution_id = NULL WHERE parent_execution_id IN (`+in+`)`, ids...); err != nil {
+		return 0, 0, fmt.Errorf("unlink child executions: %w", err)
+	}
+
+	res, err = tx.Exec(`DELETE FROM pipe_executions WHERE id IN (`+in+`)`, ids...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("delete executions: %w", err)
+	}
+	executions, _ := res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit prune: %w", err)
+	}
+
+	return int(executions), int(logs), nil
+}
+
+// Vacuum rebuilds the database file to return the space freed by deleted
+// rows to the filesystem.
+func (db *DB) Vacuum() error {
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+	return nil
+}
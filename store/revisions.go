@@ -0,0 +1,125 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// PipeRevision is a pipe as it was after one of its saves. Revisions are
+// numbered from 1 for each pipe; Config is left empty in lists.
+type PipeRevision struct {
+	ID          string `json:"id"`
+	PipeID      string `json:"pipe_id"`
+	Revision    int    `json:"revision"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Config      string `json:"config,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// addPipeRevision records the pipe as its next revision, unless its name,
+// description and config are the same as the latest one's.
+func addPipeRevision(tx *sql.Tx, pipe *Pipe) error {
+	var (
+		latest                    int
+		name, description, config string
+	)
+	err := tx.QueryRow(`
+		SELECT revision, name, description, config
+		FROM pipe_revisions
+		WHERE pipe_id = ?
+		ORDER BY revision DESC
+		LIMIT 1
+	`, pipe.ID).Scan(&latest, &name, &description, &config)
+
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("query latest revision: %w", err)
+	}
+	if err == nil && name == pipe.Name && description == pipe.Description && config == pipe.Config {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO pipe_revisions (id, pipe_id, revision, name, description, config, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, uuid.New().String(), pipe.ID, latest+1, pipe.Name, pipe.Description, pipe.Config, pipe.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("insert revision: %w", err)
+	}
+
+	return nil
+}
+
+// GetPipeRevisions lists a pipe's revisions, newest first, without their
+// configs.
+func (db *DB) GetPipeRevisions(pipeID string) ([]*PipeRevision, error) {
+	rows, err := db.Query(`
+		SELECT id, pipe_id, revision, name, description, created_at
+		FROM pipe_revisions
+		WHERE pipe_id = ?
+		ORDER BY revision DESC
+	`, pipeID)
+
+	if err != nil {
+		return nil, fmt.Errorf("query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*PipeRevision
+	for rows.Next() {
+		rev := &PipeRevision{}
+		if err := rows.Scan(&rev.ID, &rev.PipeID, &rev.Revision, &rev.Name, &rev.Description, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, nil
+}
+
+func (db *DB) GetPipeRevision(pipeID string, revision int) (*PipeRevision, error) {
+	rev := &PipeRevision{}
+
+	err := db.QueryRow(`
+		SELECT id, pipe_id, revision, name, description, config, created_at
+		FROM pipe_revisions
+		WHERE pipe_id = ? AND revision = ?
+	`, pipeID, revision).Scan(&rev.ID, &rev.PipeID, &rev.Revision, &rev.Name, &rev.Description, &rev.Config, &rev.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query revision: %w", err)
+	}
+
+	return rev, nil
+}
+
+// GetPipeRevisionAt returns the revision that was current at the given
+// time, or nil if the pipe had none yet.
+func (db *DB) GetPipeRevisionAt(pipeID string, at int64) (*PipeRevision, error) {
+	rev := &PipeRevision{}
+
+	err := db.QueryRow(`
+		SELECT id, pipe_id, revision, name, description, config, created_at
+		FROM pipe_revisions
+		WHERE pipe_id = ? AND created_at <= ?
+		ORDER BY revision DESC
+		LIMIT 1
+	`, pipeID, at).Scan(&rev.ID, &rev.PipeID, &rev.Revision, &rev.Name, &rev.Description, &rev.Config, &rev.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("query revision: %w", err)
+	}
+
+	return rev, nil
+}
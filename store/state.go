@@ -0,0 +1,133 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NodeState is a value a node keeps across runs of its pipe.
+type NodeState struct {
+	NodeID string
+	Key    string
+	Value  string
+}
+
+// SeenItem records that a node has emitted an item. ExpiresAt of 0 means the
+// record never expires.
+type SeenItem struct {
+	NodeID    string
+	ItemKey   string
+	ExpiresAt int64
+}
+
+func (db *DB) GetNodeState(pipeID, nodeID, key string) (string, bool, error) {
+	var value string
+
+	err := db.QueryRow(`
+		SELECT value FROM node_state
+		WHERE pipe_id = ? AND node_id = ? AND key = ?
+	`, pipeID, nodeID, key).Scan(&value)
+
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("get node state: %w", err)
+	}
+
+	return value, true, nil
+}
+
+// GetSeenItems returns which of keys a node has seen and that haven't
+// expired.
+func (db *DB) GetSeenItems(pipeID, nodeID string, keys []string) (map[string]bool, error) {
+	seen := make(map[string]bool)
+	now := time.Now().Unix()
+
+	// Stay well below SQLite's bound parameter limit
+	const batchSize = 500
+	for start := 0; start < len(keys); start += batchSize {
+		batch := keys[start:min(start+batchSize, len(keys))]
+
+		args := []interface{}{pipeID, nodeID, now}
+		for _, k := range batch {
+			args = append(args, k)
+		}
+
+		rows, err := db.Query(fmt.Sprintf(`
+			SELECT item_key FROM seen_items
+			WHERE pipe_id = ? AND node_id = ? AND (expires_at = 0 OR expires_at > ?)
+			AND item_key IN (%s)
+		`, strings.TrimSuffix(strings.Repeat("?,", len(batch)), ",")), args...)
+		if err != nil {
+			return nil, fmt.Errorf("query seen items: %w", err)
+		}
+
+		for rows.Next() {
+			var key string
+			if err := rows.Scan(&key); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan seen item: %w", err)
+			}
+			seen[key] = true
+		}
+		rows.Close()
+	}
+
+	return seen, nil
+}
+
+// CommitNodeState saves the state and seen items recorded during a
+// successful run in a single transaction, and drops the pipe's expired seen
+// items. Seen items keep their first_seen_at but take the new expiry.
+func (db *DB) CommitNodeState(pipeID string, state []NodeState, seen []SeenItem) error {
+	now := time.Now().Unix()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, s := range state {
+		_, err := tx.Exec(`
+			INSERT INTO node_state (pipe_id, node_id, key, value, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(pipe_id, node_id, key) DO UPDATE SET
+				value = excluded.value,
+				updated_at = excluded.updated_at
+		`, pipeID, s.NodeID, s.Key, s.Value, now)
+		if err != nil {
+			return fmt.Errorf("save node state: %w", err)
+		}
+	}
+
+	for _, item := range seen {
+		_, err := tx.Exec(`
+			INSERT INTO seen_items (pipe_id, node_id, item_key, first_seen_at, expires_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(pipe_id, node_id, item_key) DO UPDATE SET
+				expires_at = excluded.expires_at
+		`, pipeID, item.NodeID, item.ItemKey, now, item.ExpiresAt)
+		if err != nil {
+			return fmt.Errorf("save seen item: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM seen_items
+		WHERE pipe_id = ? AND expires_at > 0 AND expires_at <= ?
+	`, pipeID, now)
+	if err != nil {
+		return fmt.Errorf("delete expired seen items: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit node state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PipeWebhook is a pipe's inbound trigger URL. An empty Secret means
+// requests aren't signed.
+type PipeWebhook struct {
+	ID              string `json:"id"`
+	PipeID          string `json:"pipe_id"`
+	Token           string `json:"token"`
+	Secret          string `json:"secret,omitempty"`
+	CreatedAt       int64  `json:"created_at"`
+	LastTriggeredAt *int64 `json:"last_triggered_at,omitempty"`
+}
+
+// SavePipeWebhook creates the pipe's webhook or replaces its token and
+// secret.
+func (db *DB) SavePipeWebhook(pipeID, token, secret string) (*PipeWebhook, error) {
+	now := time.Now().Unix()
+
+	_, err := db.Exec(`
+		INSERT INTO pipe_webhooks (id, pipe_id, token, secret, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(pipe_id) DO UPDATE SET
+			token = excluded.token,
+			secret = excluded.secret,
+			created_at = excluded.created_at
+	`, uuid.New().String(), pipeID, token, secret, now)
+
+	if err != nil {
+		return nil, fmt.Errorf("save pipe webhook: %w", err)
+	}
+
+	return db.GetPipeWebhook(pipeID)
+}
+
+func (db *DB) GetPipeWebhook(pipeID string) (*PipeWebhook, error) {
+	hook := &PipeWebhook{}
+	var lastTriggeredAt sql.NullInt64
+
+	err := db.QueryRow(`
+		SELECT id, pipe_id, token, secret, created_at, last_triggered_at
+		FROM pipe_webhooks
+		WHERE pipe_id = ?
+	`, pipeID).Scan(&hook.ID, &hook.PipeID, &hook.Token, &hook.Secret, &hook.CreatedAt, &lastTriggeredAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get pipe webhook: %w", err)
+	}
+
+	if lastTriggeredAt.Valid {
+		val := lastTriggeredAt.Int64
+		hook.LastTriggeredAt = &val
+	}
+
+	return hook, nil
+}
+
+func (db *DB) DeletePipeWebhook(pipeID string) error {
+	_, err := db.Exec("DELETE FROM pipe_webhooks WHERE pipe_id = ?", pipeID)
+	if err != nil {
+		return fmt.Errorf("delete pipe webhook: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) MarkWebhookTriggered(id string) error {
+	now := time.Now().Unix()
+
+	_, err := db.Exec("UPDATE pipe_webhooks SET last_triggered_at = ? WHERE id = ?", now, id)
+	if err != nil {
+		return fmt.Errorf("mark webhook triggered: %w", err)
+	}
+
+	return nil
+}
@@ -2,17 +2,27 @@ package web
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/kierank/pipes/auth"
 	"github.com/kierank/pipes/config"
 	"github.com/kierank/pipes/engine"
+	"github.com/kierank/pipes/events"
+	"github.com/kierank/pipes/nodes"
 	"github.com/kierank/pipes/store"
 	"github.com/mmcdole/gofeed"
 )
@@ -20,6 +30,7 @@ import (
 type Server struct {
 	cfg            *config.Config
 	db             *store.DB
+	manager        *engine.Manager
 	server         *http.Server
 	sessionManager *auth.SessionManager
 	oauthClient    *auth.OAuthClient
@@ -27,10 +38,11 @@ type Server struct {
 	logger         *log.Logger
 }
 
-func NewServer(cfg *config.Config, db *store.DB, logger *log.Logger) *Server {
+func NewServer(cfg *config.Config, db *store.DB, manager *engine.Manager, logger *log.Logger) *Server {
 	return &Server{
 		cfg:            cfg,
 		db:             db,
+		manager:        manager,
 		sessionManager: auth.NewSessionManager(cfg, db),
 		oauthClient:    auth.NewOAuthClient(cfg, db),
 		logger:         logger,
@@ -68,12 +80,16 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/api/pipes", s.sessionManager.RequireAuth(s.handleAPIPipes))
 	mux.HandleFunc("/api/pipes/", s.sessionManager.RequireAuth(s.handleAPIPipe))
 	mux.HandleFunc("/api/node-types", s.handleAPINodeTypes)
+	mux.HandleFunc("/api/node-ports", s.sessionManager.RequireAuth(s.handleAPINodePorts))
 	mux.HandleFunc("/api/executions/", s.sessionManager.RequireAuth(s.handleAPIExecution))
 	mux.HandleFunc("/api/feed-info", s.sessionManager.RequireAuth(s.handleAPIFeedInfo))
 
 	// Public feed routes
 	mux.HandleFunc("/feeds/", s.handlePublicFeed)
 
+	// Inbound webhook triggers (authenticated by token and signature)
+	mux.HandleFunc("/hooks/", s.handleWebhookTrigger)
+
 	s.server = &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port),
 		Handler: mux,
@@ -252,15 +268,39 @@ func (s *Server) handleAPIPipes(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if req.Config == "" {
-			req.Config = `{"version":"1","nodes":[],"connections":[],"settings":{"enabled":false}}`
+			req.Config = `{"nodes":[],"connections":[],"settings":{"enabled":false}}`
+		}
+
+		config, err := parsePipeConfig(req.Config)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := checkSchedulable(config); err != nil {
+			writeUnschedulable(w, err)
+			return
 		}
 
-		pipe, err := s.db.CreatePipe(user.ID, req.Name, req.Description, req.Config, false)
+		// Store the config as upgraded to the current version
+		configJSON, _ := json.Marshal(config)
+
+		pipe, err := s.db.CreatePipe(user.ID, req.Name, req.Description, string(configJSON), false)
 		if err != nil {
 			http.Error(w, "Failed to create pipe", http.StatusInternalServerError)
 			return
 		}
 
+		// Don't leave a pipe behind that a retry would duplicate
+		if err := engine.SyncSchedule(s.db, pipe.ID, &config.Settings); err != nil {
+			s.logger.Error("failed to sync schedule", "pipe_id", pipe.ID, "error", err)
+			if err := s.db.DeletePipe(pipe.ID); err != nil {
+				s.logger.Error("failed to delete unscheduled pipe", "pipe_id", pipe.ID, "error", err)
+			}
+			http.Error(w, "Failed to schedule pipe", http.StatusInternalServerError)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(pipe)
@@ -294,6 +334,41 @@ func (s *Server) handleAPIPipe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check if it's a schedule request
+	if len(path) > 9 && path[len(path)-9:] == "/schedule" {
+		pipeID := path[:len(path)-9]
+		s.handlePipeSchedule(w, r, pipeID, user)
+		return
+	}
+
+	// Check if it's a webhook request
+	if len(path) > 8 && path[len(path)-8:] == "/webhook" {
+		pipeID := path[:len(path)-8]
+		s.handlePipeWebhook(w, r, pipeID, user)
+		return
+	}
+
+	// Check if it's a validate request
+	if len(path) > 9 && path[len(path)-9:] == "/validate" {
+		pipeID := path[:len(path)-9]
+		s.handlePipeValidate(w, r, pipeID, user)
+		return
+	}
+
+	// Check if it's a preview request
+	if len(path) > 8 && path[len(path)-8:] == "/preview" {
+		pipeID := path[:len(path)-8]
+		s.handlePipePreview(w, r, pipeID, user)
+		return
+	}
+
+	// Check if it's a revisions request: /revisions, /revisions/{n},
+	// /revisions/{n}/diff or /revisions/{n}/restore
+	if pipeID, rest, ok := strings.Cut(path, "/revisions"); ok && (rest == "" || rest[0] == '/') {
+		s.handlePipeRevisions(w, r, pipeID, strings.TrimPrefix(rest, "/"), user)
+		return
+	}
+
 	pipeID := path
 
 	switch r.Method {
@@ -309,8 +384,27 @@ func (s *Server) handleAPIPipe(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// The editor only knows the current version's node configs
+		if config, err := engine.ParseConfig(pipe.Config); err == nil {
+			configJSON, _ := json.Marshal(config)
+			pipe.Config = string(configJSON)
+		}
+
+		resp := struct {
+			*store.Pipe
+			Job *store.ScheduledJob `json:"job"`
+		}{Pipe: pipe}
+
+		if pipe.UserID == user.ID {
+			job, err := s.db.GetScheduledJobByPipe(pipe.ID)
+			if err != nil {
+				s.logger.Error("failed to get scheduled job", "pipe_id", pipe.ID, "error", err)
+			}
+			resp.Job = job
+		}
+
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(pipe)
+		json.NewEncoder(w).Encode(resp)
 
 	case "PUT":
 		pipe, err := s.db.GetPipe(pipeID)
@@ -342,15 +436,42 @@ func (s *Server) handleAPIPipe(w http.ResponseWriter, r *http.Request) {
 		if req.Description != "" {
 			pipe.Description = req.Description
 		}
+		var config *engine.PipeConfig
 		if req.Config != nil {
 			configJSON, _ := json.Marshal(req.Config)
+			config, err = parsePipeConfig(string(configJSON))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			configJSON, _ = json.Marshal(config)
 			pipe.Config = string(configJSON)
 		}
 		if req.IsPublic != nil {
 			pipe.IsPublic = *req.IsPublic
 		}
 
-		if err := s.db.UpdatePipe(pipe); err != nil {
+		if config != nil {
+			if err := checkSchedulable(config); err != nil {
+				writeUnschedulable(w, err)
+				return
+			}
+		}
+
+		// A new config is saved together with its scheduled job, so a
+		// failure leaves both as they were
+		if config == nil {
+			err = s.db.UpdatePipe(pipe)
+		} else {
+			job, planErr := engine.PlanSchedule(pipe.ID, &config.Settings)
+			if planErr != nil {
+				http.Error(w, planErr.Error(), http.StatusBadRequest)
+				return
+			}
+			err = s.db.UpdatePipeSchedule(pipe, job)
+		}
+		if err != nil {
+			s.logger.Error("failed to update pipe", "pipe_id", pipe.ID, "error", err)
 			http.Error(w, "Failed to update pipe", http.StatusInternalServerError)
 			return
 		}
@@ -385,16 +506,19 @@ func (s *Server) handleAPIPipe(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleAPINodeTypes(w http.ResponseWriter, r *http.Request) {
 	registry := engine.NewRegistry()
-	nodes := registry.GetAll()
 
 	var nodeTypes []map[string]interface{}
-	for _, node := range nodes {
+	for _, node := range registry.GetAll() {
 		nodeTypes = append(nodeTypes, map[string]interface{}{
 			"type":        node.Type(),
 			"label":       node.Label(),
 			"description": node.Description(),
 			"category":    node.Category(),
 			"schema":      node.GetConfigSchema(),
+			"inputs":      node.Inputs(),
+			"outputs":     node.Outputs(),
+			"inputPorts":  nodes.InputPorts(node),
+			"outputPorts": nodes.OutputPorts(node, nil),
 		})
 	}
 
@@ -402,6 +526,45 @@ func (s *Server) handleAPINodeTypes(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(nodeTypes)
 }
 
+// handleAPINodePorts resolves the ports of configured nodes, for node types
+// whose outputs depend on their config (e.g. one branch per Switch rule).
+func (s *Server) handleAPINodePorts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Nodes []engine.Node `json:"nodes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	registry := engine.NewRegistry()
+
+	result := make(map[string]interface{}, len(req.Nodes))
+	for _, n := range req.Nodes {
+		node, err := registry.Get(n.Type)
+		if err != nil {
+			continue
+		}
+
+		ports := map[string]interface{}{
+			"inputPorts":  nodes.InputPorts(node),
+			"outputPorts": nodes.OutputPorts(node, n.Config),
+		}
+		if err := node.ValidateConfig(n.Config); err != nil {
+			ports["error"] = err.Error()
+		}
+		result[n.ID] = ports
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 func (s *Server) handleAPIFeedInfo(w http.ResponseWriter, r *http.Request) {
 	url := r.URL.Query().Get("url")
 	if url == "" {
@@ -443,18 +606,18 @@ func (s *Server) handlePipeExecute(w http.ResponseWriter, r *http.Request, pipeI
 	}
 
 	// Execute the pipe
-	executor := engine.NewExecutor(s.db)
-	executionID, err := executor.Execute(r.Context(), pipeID, "manual")
+	executionID, err := s.manager.Start(pipeID, engine.ExecuteOptions{TriggerType: "manual"})
 	if err != nil {
-		s.logger.Error("pipe execution failed", "pipe_id", pipeID, "error", err)
+		s.logger.Error("failed to start execution", "pipe_id", pipeID, "error", err)
 		http.Error(w, fmt.Sprintf("Execution failed: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]string{
 		"executionId": executionID,
-		"status":      "started",
+		"status":      "running",
 	})
 }
 
@@ -495,6 +658,511 @@ func (s *Server) handlePipeExecutions(w http.ResponseWriter, r *http.Request, pi
 	json.NewEncoder(w).Encode(executions)
 }
 
+func (s *Server) handlePipeSchedule(w http.ResponseWriter, r *http.Request, pipeID string, user *store.User) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pipe, err := s.db.GetPipe(pipeID)
+	if err != nil || pipe == nil {
+		http.Error(w, "Pipe not found", http.StatusNotFound)
+		return
+	}
+
+	if pipe.UserID != user.ID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	config, err := parsePipeConfig(pipe.Config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// Get count from query params
+	count := 5
+	if countStr := r.URL.Query().Get("count"); countStr != "" {
+		if c, err := strconv.Atoi(countStr); err == nil && c > 0 && c <= 50 {
+			count = c
+		}
+	}
+
+	nextRuns := []int64{}
+	if sched, _ := config.Settings.ParseSchedule(); sched != nil {
+		for _, t := range sched.NextN(time.Now(), count) {
+			nextRuns = append(nextRuns, t.Unix())
+		}
+	}
+
+	job, err := s.db.GetScheduledJobByPipe(pipeID)
+	if err != nil {
+		s.logger.Error("failed to get scheduled job", "pipe_id", pipeID, "error", err)
+		http.Error(w, "Failed to get schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"schedule":  config.Settings.Schedule,
+		"timezone":  config.Settings.Timezone,
+		"enabled":   config.Settings.Enabled,
+		"next_runs": nextRuns,
+		"job":       job,
+	})
+}
+
+// handlePipeValidate checks a pipe's config and lists every problem found,
+// by node. The body can hold {"config": ...} to check unsaved changes
+// instead of the stored config.
+func (s *Server) handlePipeValidate(w http.ResponseWriter, r *http.Request, pipeID string, user *store.User) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pipe, err := s.db.GetPipe(pipeID)
+	if err != nil || pipe == nil {
+		http.Error(w, "Pipe not found", http.StatusNotFound)
+		return
+	}
+
+	if pipe.UserID != user.ID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Config json.RawMessage `json:"config"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	configJSON := pipe.Config
+	if len(req.Config) > 0 && string(req.Config) != "null" {
+		configJSON = string(req.Config)
+	}
+
+	errs := engine.ValidationErrors{}
+	config, err := parsePipeConfig(configJSON)
+	if err != nil {
+		errs = append(errs, engine.ValidationError{Message: err.Error()})
+	} else if verrs := engine.NewRegistry().Validate(config); verrs != nil {
+		errs = verrs
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"valid":  len(errs) == 0,
+		"errors": errs,
+	})
+}
+
+// handlePipePreview runs the nodes a node depends on without side effects
+// and returns a sample of the node's output. The body holds {"node_id":
+// ...}, and optionally "config" to preview unsaved changes, "use_cache" to
+// serve sources from the cache, "limit" and "payload".
+func (s *Server) handlePipePreview(w http.ResponseWriter, r *http.Request, pipeID string, user *store.User) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pipe, err := s.db.GetPipe(pipeID)
+	if err != nil || pipe == nil {
+		http.Error(w, "Pipe not found", http.StatusNotFound)
+		return
+	}
+
+	if pipe.UserID != user.ID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		NodeID   string          `json:"node_id"`
+		Config   json.RawMessage `json:"config"`
+		UseCache bool            `json:"use_cache"`
+		Limit    int             `json:"limit"`
+		Payload  interface{}     `json:"payload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.NodeID == "" {
+		http.Error(w, "node_id is required", http.StatusBadRequest)
+		return
+	}
+
+	opts := engine.PreviewOptions{
+		UseCache: req.UseCache,
+		Limit:    req.Limit,
+		Payload:  req.Payload,
+	}
+	if len(req.Config) > 0 && string(req.Config) != "null" {
+		if opts.Config, err = engine.ParseConfig(string(req.Config)); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	result, err := s.manager.Preview(r.Context(), pipe, req.NodeID, opts)
+	if err != nil {
+		writeRunError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeRunError responds to a preview or replay that couldn't start. A
+// config that isn't valid is reported as {"errors": [...]}, by node.
+func writeRunError(w http.ResponseWriter, err error) {
+	var verrs engine.ValidationErrors
+	if errors.As(err, &verrs) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": verrs})
+		return
+	}
+	http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+}
+
+// handlePipeRevisions serves a pipe's saved revisions. GET lists them, or
+// returns one with its config at the current version. GET {n}/diff
+// compares revision n with another (?to=, by default the latest), and
+// POST {n}/restore saves revision n as the pipe's newest revision.
+func (s *Server) handlePipeRevisions(w http.ResponseWriter, r *http.Request, pipeID, rest string, user *store.User) {
+	pipe, err := s.db.GetPipe(pipeID)
+	if err != nil || pipe == nil {
+		http.Error(w, "Pipe not found", http.StatusNotFound)
+		return
+	}
+
+	if pipe.UserID != user.ID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if rest == "" {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		revisions, err := s.db.GetPipeRevisions(pipeID)
+		if err != nil {
+			s.logger.Error("failed to list revisions", "pipe_id", pipeID, "error", err)
+			http.Error(w, "Failed to load revisions", http.StatusInternalServerError)
+			return
+		}
+		if revisions == nil {
+			revisions = []*store.PipeRevision{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(revisions)
+		return
+	}
+
+	number, action, _ := strings.Cut(rest, "/")
+	revision, ok := s.loadRevision(w, pipeID, number)
+	if !ok {
+		return
+	}
+
+	config, err := parsePipeConfig(revision.Config)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Revision %d: %v", revision.Revision, err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == "GET":
+		configJSON, _ := json.Marshal(config)
+		revision.Config = string(configJSON)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(revision)
+
+	case action == "diff" && r.Method == "GET":
+		to := r.URL.Query().Get("to")
+		if to == "" {
+			to = "latest"
+		}
+		toRevision, ok := s.loadRevision(w, pipeID, to)
+		if !ok {
+			return
+		}
+
+		toConfig, err := parsePipeConfig(toRevision.Config)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Revision %d: %v", toRevision.Revision, err), http.StatusUnprocessableEntity)
+			return
+		}
+
+		// Configs are left out; the diff says what's different about them
+		revision.Config, toRevision.Config = "", ""
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"from": revision,
+			"to":   toRevision,
+			"diff": engine.DiffConfigs(config, toConfig),
+		})
+
+	case action == "restore" && r.Method == "POST":
+		if err := checkSchedulable(config); err != nil {
+			writeUnschedulable(w, err)
+			return
+		}
+
+		job, err := engine.PlanSchedule(pipe.ID, &config.Settings)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Revision %d: %v", revision.Revision, err), http.StatusBadRequest)
+			return
+		}
+
+		configJSON, _ := json.Marshal(config)
+		pipe.Name = revision.Name
+		pipe.Description = revision.Description
+		pipe.Config = string(configJSON)
+
+		if err := s.db.UpdatePipeSchedule(pipe, job); err != nil {
+			s.logger.Error("failed to restore revision", "pipe_id", pipeID, "revision", revision.Revision, "error", err)
+			http.Error(w, "Failed to restore revision", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+
+	case action == "" || action == "diff" || action == "restore":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// loadRevision gets a pipe's revision by number, or its newest one for
+// "latest", writing an error response if there isn't one.
+func (s *Server) loadRevision(w http.ResponseWriter, pipeID, number string) (*store.PipeRevision, bool) {
+	var n int
+	if number == "latest" {
+		revisions, err := s.db.GetPipeRevisions(pipeID)
+		if err != nil {
+			s.logger.Error("failed to list revisions", "pipe_id", pipeID, "error", err)
+			http.Error(w, "Failed to load revision", http.StatusInternalServerError)
+			return nil, false
+		}
+		if len(revisions) > 0 {
+			n = revisions[0].Revision
+		}
+	} else if v, err := strconv.Atoi(number); err == nil {
+		n = v
+	}
+
+	revision, err := s.db.GetPipeRevision(pipeID, n)
+	if err != nil {
+		s.logger.Error("failed to get revision", "pipe_id", pipeID, "revision", number, "error", err)
+		http.Error(w, "Failed to load revision", http.StatusInternalServerError)
+		return nil, false
+	}
+	if revision == nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return revision, true
+}
+
+// maxWebhookPayload bounds the size of a webhook trigger's request body.
+const maxWebhookPayload = 1 << 20
+
+// handlePipeWebhook manages a pipe's inbound trigger URL. POST creates it or
+// rotates its token (with {"signed": true} to also generate an HMAC
+// secret), DELETE removes it.
+func (s *Server) handlePipeWebhook(w http.ResponseWriter, r *http.Request, pipeID string, user *store.User) {
+	pipe, err := s.db.GetPipe(pipeID)
+	if err != nil || pipe == nil {
+		http.Error(w, "Pipe not found", http.StatusNotFound)
+		return
+	}
+
+	if pipe.UserID != user.ID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var hook *store.PipeWebhook
+
+	switch r.Method {
+	case "GET":
+		hook, err = s.db.GetPipeWebhook(pipeID)
+		if err != nil {
+			s.logger.Error("failed to get webhook", "pipe_id", pipeID, "error", err)
+			http.Error(w, "Failed to get webhook", http.StatusInternalServerError)
+			return
+		}
+
+	case "POST":
+		var req struct {
+			Signed bool `json:"signed"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+
+		token, err := randomToken()
+		if err != nil {
+			http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+			return
+		}
+
+		var secret string
+		if req.Signed {
+			if secret, err = randomToken(); err != nil {
+				http.Error(w, "Failed to generate secret", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		hook, err = s.db.SavePipeWebhook(pipeID, token, secret)
+		if err != nil {
+			s.logger.Error("failed to save webhook", "pipe_id", pipeID, "error", err)
+			http.Error(w, "Failed to save webhook", http.StatusInternalServerError)
+			return
+		}
+
+	case "DELETE":
+		if err := s.db.DeletePipeWebhook(pipeID); err != nil {
+			s.logger.Error("failed to delete webhook", "pipe_id", pipeID, "error", err)
+			http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := map[string]interface{}{"webhook": hook}
+	if hook != nil {
+		resp["url"] = fmt.Sprintf("%s/hooks/%s/%s", s.cfg.Origin, pipeID, hook.Token)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleWebhookTrigger runs a pipe from its inbound webhook URL,
+// /hooks/{pipe}/{token}. If the webhook has a secret, the request must carry
+// a GitHub-style X-Hub-Signature-256 header. The JSON body is available to
+// the pipe through the Trigger Payload node.
+func (s *Server) handleWebhookTrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/hooks/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	pipeID, token := parts[0], parts[1]
+
+	hook, err := s.db.GetPipeWebhook(pipeID)
+	if err != nil {
+		s.logger.Error("failed to get webhook", "pipe_id", pipeID, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if hook == nil || subtle.ConstantTimeCompare([]byte(hook.Token), []byte(token)) != 1 {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookPayload))
+	if err != nil {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if hook.Secret != "" && !validSignature(hook.Secret, body, r.Header.Get("X-Hub-Signature-256")) {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload interface{}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, "Body must be JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.db.MarkWebhookTriggered(hook.ID)
+
+	executionID, err := s.manager.Start(pipeID, engine.ExecuteOptions{
+		TriggerType: "webhook",
+		Payload:     payload,
+	})
+	if err != nil {
+		s.logger.Error("failed to start webhook execution", "pipe_id", pipeID, "error", err)
+		http.Error(w, fmt.Sprintf("Execution failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"executionId": executionID,
+		"status":      "running",
+	})
+}
+
+// validSignature checks an X-Hub-Signature-256 header ("sha256=<hex>")
+// against the HMAC-SHA256 of the body.
+func validSignature(secret string, body []byte, header string) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 func (s *Server) handleAPIExecution(w http.ResponseWriter, r *http.Request) {
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
@@ -512,32 +1180,266 @@ func (s *Server) handleAPIExecution(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check if it's a stream request
+	if len(path) > 7 && path[len(path)-7:] == "/stream" {
+		executionID := path[:len(path)-7]
+		s.handleExecutionStream(w, r, executionID, user)
+		return
+	}
+
+	// Check if it's a cancel request
+	if len(path) > 7 && path[len(path)-7:] == "/cancel" {
+		executionID := path[:len(path)-7]
+		s.handleExecutionCancel(w, r, executionID, user)
+		return
+	}
+
+	// Check if it's a replay request
+	if len(path) > 7 && path[len(path)-7:] == "/replay" {
+		executionID := path[:len(path)-7]
+		s.handleExecutionReplay(w, r, executionID, user)
+		return
+	}
+
+	if path != "" && !strings.Contains(path, "/") {
+		s.handleExecutionStatus(w, r, path, user)
+		return
+	}
+
 	http.Error(w, "Not found", http.StatusNotFound)
 }
 
-func (s *Server) handleExecutionLogs(w http.ResponseWriter, r *http.Request, executionID string, user *store.User) {
-	// Get the execution to check ownership
+// getOwnedExecution loads an execution and checks that the user owns its
+// pipe, writing an error response and returning nil otherwise.
+func (s *Server) getOwnedExecution(w http.ResponseWriter, executionID string, user *store.User) *store.PipeExecution {
 	exec, err := s.db.GetExecution(executionID)
 	if err != nil {
 		s.logger.Error("failed to get execution", "execution_id", executionID, "error", err)
 		http.Error(w, "Failed to get execution", http.StatusInternalServerError)
-		return
+		return nil
 	}
 
 	if exec == nil {
 		http.Error(w, "Execution not found", http.StatusNotFound)
-		return
+		return nil
 	}
 
 	// Verify user owns the pipe
 	pipe, err := s.db.GetPipe(exec.PipeID)
 	if err != nil || pipe == nil {
 		http.Error(w, "Pipe not found", http.StatusNotFound)
-		return
+		return nil
 	}
 
 	if pipe.UserID != user.ID {
 		http.Error(w, "Forbidden", http.StatusForbidden)
+		return nil
+	}
+
+	return exec
+}
+
+func (s *Server) handleExecutionStatus(w http.ResponseWriter, r *http.Request, executionID string, user *store.User) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	exec := s.getOwnedExecution(w, executionID, user)
+	if exec == nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exec)
+}
+
+func (s *Server) handleExecutionCancel(w http.ResponseWriter, r *http.Request, executionID string, user *store.User) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	exec := s.getOwnedExecution(w, executionID, user)
+	if exec == nil {
+		return
+	}
+
+	if !s.manager.Cancel(executionID, engine.ErrCancelled) {
+		http.Error(w, fmt.Sprintf("Execution is not running (status: %s)", exec.Status), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"executionId": executionID,
+		"status":      "cancelling",
+	})
+}
+
+// handleExecutionReplay re-runs an execution's pipe against the output its
+// sources recorded, and compares each node's output with the original run.
+// The body can hold {"config": ...} to replay unsaved changes instead of
+// the config the execution ran with, and "limit".
+func (s *Server) handleExecutionReplay(w http.ResponseWriter, r *http.Request, executionID string, user *store.User) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	exec := s.getOwnedExecution(w, executionID, user)
+	if exec == nil {
+		return
+	}
+
+	if exec.Status == "running" {
+		http.Error(w, "Execution is still running", http.StatusConflict)
+		return
+	}
+
+	pipe, err := s.db.GetPipe(exec.PipeID)
+	if err != nil || pipe == nil {
+		http.Error(w, "Pipe not found", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		Config json.RawMessage `json:"config"`
+		Limit  int             `json:"limit"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	opts := engine.ReplayOptions{Limit: req.Limit}
+	if len(req.Config) > 0 && string(req.Config) != "null" {
+		if opts.Config, err = engine.ParseConfig(string(req.Config)); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	result, err := s.manager.Replay(r.Context(), pipe, executionID, opts)
+	if err != nil {
+		writeRunError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// sseHeartbeat keeps idle event streams from being closed by proxies.
+const sseHeartbeat = 15 * time.Second
+
+// handleExecutionStream streams an execution's logs, node start/finish
+// events and final status as Server-Sent Events. Events published before
+// the client connected are replayed first; a finished execution is replayed
+// from the stored logs. The stream ends with an "end" event.
+func (s *Server) handleExecutionStream(w http.ResponseWriter, r *http.Request, executionID string, user *store.User) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	exec := s.getOwnedExecution(w, executionID, user)
+	if exec == nil {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	send := func(eventType string, data interface{}) {
+		payload, _ := json.Marshal(data)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, payload)
+		flusher.Flush()
+	}
+
+	history, ch, unsubscribe, live := s.manager.Events().Subscribe(executionID)
+	defer unsubscribe()
+
+	if !live {
+		s.replayExecution(exec, send)
+		send("end", map[string]string{"execution_id": executionID})
+		return
+	}
+
+	for _, event := range history {
+		send(event.Type, event)
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				send("end", map[string]string{"execution_id": executionID})
+				return
+			}
+			send(event.Type, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// replayExecution sends the stored logs and status of an execution that is
+// no longer on the event bus.
+func (s *Server) replayExecution(exec *store.PipeExecution, send func(string, interface{})) {
+	logs, err := s.db.GetExecutionLogs(exec.ID)
+	if err != nil {
+		s.logger.Error("failed to get logs", "execution_id", exec.ID, "error", err)
+	}
+
+	for _, l := range logs {
+		event := events.Event{
+			Type:        events.TypeLog,
+			ExecutionID: exec.ID,
+			NodeID:      l.NodeID,
+			Level:       l.Level,
+			Message:     l.Message,
+			Timestamp:   l.Timestamp,
+		}
+		if l.Metadata != nil && json.Valid([]byte(*l.Metadata)) {
+			event.Data = json.RawMessage(*l.Metadata)
+		}
+		send(event.Type, event)
+	}
+
+	status := events.Event{
+		Type:        events.TypeStatus,
+		ExecutionID: exec.ID,
+		Status:      exec.Status,
+		Items:       exec.ItemsProcessed,
+	}
+	if exec.ErrorMessage != nil {
+		status.Message = *exec.ErrorMessage
+	}
+	if exec.CompletedAt != nil {
+		status.Timestamp = *exec.CompletedAt
+	}
+	send(status.Type, status)
+}
+
+func (s *Server) handleExecutionLogs(w http.ResponseWriter, r *http.Request, executionID string, user *store.User) {
+	if s.getOwnedExecution(w, executionID, user) == nil {
 		return
 	}
 
@@ -602,8 +1504,7 @@ func (s *Server) handlePublicFeed(w http.ResponseWriter, r *http.Request) {
 
 	// Auto-run if no output exists
 	if output == nil {
-		executor := engine.NewExecutor(s.db)
-		_, err := executor.Execute(r.Context(), pipe.ID, "auto")
+		_, err := s.manager.Run(r.Context(), pipe.ID, engine.ExecuteOptions{TriggerType: "auto"})
 		if err != nil {
 			s.logger.Error("auto-execute failed", "pipe_id", pipe.ID, "error", err)
 			http.Error(w, "Failed to generate feed", http.StatusInternalServerError)
@@ -625,6 +1526,49 @@ func (s *Server) handlePublicFeed(w http.ResponseWriter, r *http.Request) {
 
 // Helper functions
 
+// parsePipeConfig decodes a pipe config, upgrading it to the current
+// version, and checks that its schedule, if any, is a valid cron
+// expression.
+func parsePipeConfig(configJSON string) (*engine.PipeConfig, error) {
+	config, err := engine.ParseConfig(configJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	sched, err := config.Settings.ParseSchedule()
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	if sched != nil && sched.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid schedule: %q never fires", config.Settings.Schedule)
+	}
+
+	return config, nil
+}
+
+// checkSchedulable refuses to enable the schedule of a pipe that isn't
+// valid. Drafts can be saved with problems, but not scheduled.
+func checkSchedulable(config *engine.PipeConfig) error {
+	if !config.Settings.Enabled || config.Settings.Schedule == "" {
+		return nil
+	}
+	if errs := engine.NewRegistry().Validate(config); errs != nil {
+		return fmt.Errorf("can't enable the schedule: %w", errs)
+	}
+	return nil
+}
+
+// writeUnschedulable responds to a save that checkSchedulable refused, with
+// the problems by node under "errors" so the editor can outline them.
+func writeUnschedulable(w http.ResponseWriter, err error) {
+	var verrs engine.ValidationErrors
+	errors.As(err, &verrs)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "errors": verrs})
+}
+
 func (s *Server) renderError(w http.ResponseWriter, title, message, details string) {
 	w.Header().Set("Content-Type", "text/html")
 	w.WriteHeader(http.StatusBadRequest)